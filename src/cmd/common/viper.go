@@ -12,11 +12,12 @@ import (
 )
 
 const (
-	VLogLevel  = "log_level"
-	VTarget    = "target"
-	VSummary   = "summary"
-	VConstants = "constants"
-	VVariables = "variables"
+	VLogLevel         = "log_level"
+	VTarget           = "target"
+	VSummary          = "summary"
+	VConstants        = "constants"
+	VVariables        = "variables"
+	VOpaAllowBuiltins = "opa_allow_builtins"
 )
 
 var (
@@ -116,6 +117,7 @@ func setDefaults() {
 	v.SetDefault(VSummary, false)
 	v.SetDefault(VConstants, make(map[string]interface{}))
 	v.SetDefault(VVariables, make([]interface{}, 0))
+	v.SetDefault(VOpaAllowBuiltins, make([]string, 0))
 }
 
 func printViperConfigUsed() {
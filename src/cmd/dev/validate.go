@@ -29,6 +29,8 @@ To hang indefinitely for stdin:
 	lula dev validate -t -1
 To hang for timeout of 5 seconds:
 	lula dev validate -t 5
+To print the output of print statements and the evaluation trace of the validation:
+	lula dev validate -f /path/to/validation.yaml --trace
 `
 
 func DevValidateCommand() *cobra.Command {
@@ -42,6 +44,7 @@ func DevValidateCommand() *cobra.Command {
 		resourcesFile      string // -r --resources-file
 		runTests           bool   // --run-tests
 		printTestResources bool   // --print-test-resources
+		trace              bool   // --trace
 	)
 
 	cmd := &cobra.Command{
@@ -93,6 +96,7 @@ func DevValidateCommand() *cobra.Command {
 			message.Debugf("templated validation: %s", string(output))
 
 			ctx = context.WithValue(ctx, types.LulaValidationWorkDir, filepath.Dir(inputFile))
+			ctx = context.WithValue(ctx, types.LulaValidationTrace, trace)
 			validation, err := DevValidate(ctx, output, resourcesBytes, confirmExecution, spinner)
 			if err != nil {
 				return fmt.Errorf("error running dev validate: %v", err)
@@ -113,6 +117,17 @@ func DevValidateCommand() *cobra.Command {
 				}
 			}
 
+			// Print the debugging output if there is any - prints are captured with --trace or at the debug log level
+			if len(validation.Result.Prints) > 0 {
+				message.Infof("Print output:")
+				for _, msg := range validation.Result.Prints {
+					message.Infof("--> %s", msg)
+				}
+			}
+			if validation.Result.Trace != "" {
+				message.Infof("Trace:\n%s", validation.Result.Trace)
+			}
+
 			result := validation.Result.Passing > 0 && validation.Result.Failing <= 0
 			// If the expected result is not equal to the actual result, return an error
 			if expectedResult != result {
@@ -152,6 +167,7 @@ func DevValidateCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&confirmExecution, "confirm-execution", false, "confirm execution scripts run as part of the validation")
 	cmd.Flags().BoolVar(&runTests, "run-tests", false, "run tests specified in the validation")
 	cmd.Flags().BoolVar(&printTestResources, "print-test-resources", false, "whether to print resources used for tests; prints <test-name>.json to the validation directory")
+	cmd.Flags().BoolVar(&trace, "trace", false, "print the output of print statements and the evaluation trace of the validation and its tests")
 
 	return cmd
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 
 	"github.com/mike-winberry/lulalib/src/cmd/common"
@@ -15,15 +17,28 @@ import (
 	"github.com/mike-winberry/lulalib/src/cmd/tools"
 	"github.com/mike-winberry/lulalib/src/cmd/validate"
 	"github.com/mike-winberry/lulalib/src/cmd/version"
+	"github.com/mike-winberry/lulalib/src/pkg/message"
+	"github.com/mike-winberry/lulalib/src/pkg/providers/opa"
+	"github.com/mike-winberry/lulalib/src/types"
 	"github.com/spf13/cobra"
 )
 
-var LogLevelCLI string
+var (
+	LogLevelCLI         string
+	OpaAllowBuiltinsCLI []string
+)
 
 var rootCmd = &cobra.Command{
 	Use: "lula",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		common.SetupClI(LogLevelCLI)
+		for _, name := range OpaAllowBuiltinsCLI {
+			if !slices.Contains(opa.RestrictedBuiltins, name) {
+				message.Warnf("%s is not a restricted OPA built-in function", name)
+			}
+		}
+		// Restricted OPA built-ins are denied unless allowed
+		cmd.SetContext(context.WithValue(cmd.Context(), types.LulaValidationAllowedBuiltins, OpaAllowBuiltinsCLI))
 	},
 	Short: "Risk Management as Code",
 	Long:  `Real Time Risk Transparency through automated validation`,
@@ -72,4 +87,5 @@ func init() {
 	version.Include(rootCmd)
 
 	rootCmd.PersistentFlags().StringVarP(&LogLevelCLI, "log-level", "l", v.GetString(common.VLogLevel), "Log level when running Lula. Valid options are: warn, info, debug, trace")
+	rootCmd.PersistentFlags().StringSliceVar(&OpaAllowBuiltinsCLI, "opa-allow-builtins", v.GetStringSlice(common.VOpaAllowBuiltins), fmt.Sprintf("Restricted OPA built-in functions that policies are allowed to call. Valid options are: %s", strings.Join(opa.RestrictedBuiltins, ", ")))
 }
@@ -17,10 +17,24 @@ import (
 
 	"github.com/mike-winberry/lulalib/src/pkg/common/network"
 	"github.com/mike-winberry/lulalib/src/pkg/domains/api"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/command"
 	"github.com/mike-winberry/lulalib/src/pkg/domains/files"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/git"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/host"
 	kube "github.com/mike-winberry/lulalib/src/pkg/domains/kubernetes"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/logs"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/multi"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/oci"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/prometheus"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sbom"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sql"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/terraform"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/tls"
 	"github.com/mike-winberry/lulalib/src/pkg/message"
+	"github.com/mike-winberry/lulalib/src/pkg/providers/cel"
+	"github.com/mike-winberry/lulalib/src/pkg/providers/jsonschema"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/kyverno"
+	multiprovider "github.com/mike-winberry/lulalib/src/pkg/providers/multi"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/opa"
 	"github.com/mike-winberry/lulalib/src/types"
 )
@@ -155,11 +169,49 @@ func GetDomain(domain *Domain) (types.Domain, error) {
 		return api.CreateApiDomain(domain.ApiSpec)
 	case "file":
 		return files.CreateDomain(domain.FileSpec)
+	case "command":
+		return command.CreateCommandDomain(domain.CommandSpec)
+	case "host":
+		return host.CreateHostDomain(domain.HostSpec)
+	case "oci":
+		return oci.CreateOciDomain(domain.OciSpec)
+	case "sbom":
+		return sbom.CreateSbomDomain(domain.SbomSpec)
+	case "terraform":
+		return terraform.CreateTerraformDomain(domain.TerraformSpec)
+	case "tls":
+		return tls.CreateTlsDomain(domain.TlsSpec)
+	case "sql":
+		return sql.CreateSqlDomain(domain.SqlSpec)
+	case "git":
+		return git.CreateGitDomain(domain.GitSpec)
+	case "prometheus":
+		return prometheus.CreatePrometheusDomain(domain.PrometheusSpec)
+	case "logs":
+		return logs.CreateLogsDomain(domain.LogsSpec)
 	default:
 		return nil, fmt.Errorf("domain is unsupported")
 	}
 }
 
+// GetMultiDomain returns a domain that collects the resources of each of the domains, keyed by name
+func GetMultiDomain(domains []NamedDomain) (types.Domain, error) {
+	named := make([]multi.NamedDomain, 0, len(domains))
+	var errs error
+	for _, d := range domains {
+		domain, err := GetDomain(&d.Domain)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("domain %s: %w", d.Name, err))
+			continue
+		}
+		named = append(named, multi.NamedDomain{Name: d.Name, Domain: domain})
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return multi.CreateMultiDomain(named)
+}
+
 func GetProvider(provider *Provider, ctx context.Context) (types.Provider, error) {
 	if provider == nil {
 		return nil, fmt.Errorf("provider is nil")
@@ -169,11 +221,34 @@ func GetProvider(provider *Provider, ctx context.Context) (types.Provider, error
 		return opa.CreateOpaProvider(ctx, provider.OpaSpec)
 	case "kyverno":
 		return kyverno.CreateKyvernoProvider(ctx, provider.KyvernoSpec)
+	case "cel":
+		return cel.CreateCelProvider(ctx, provider.CelSpec)
+	case "jsonschema":
+		return jsonschema.CreateJsonSchemaProvider(ctx, provider.JsonSchemaSpec)
 	default:
 		return nil, fmt.Errorf("provider is unsupported")
 	}
 }
 
+// GetMultiProvider returns a provider that evaluates the resources with each of the providers and
+// combines their results
+func GetMultiProvider(providers []NamedProvider, combine string, threshold int, ctx context.Context) (types.Provider, error) {
+	named := make([]multiprovider.NamedProvider, 0, len(providers))
+	var errs error
+	for _, p := range providers {
+		provider, err := GetProvider(&p.Provider, ctx)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("provider %s: %w", p.Name, err))
+			continue
+		}
+		named = append(named, multiprovider.NamedProvider{Name: p.Name, Provider: provider})
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return multiprovider.CreateMultiProvider(named, combine, threshold)
+}
+
 // Converts a raw string to a Validation object (string -> common.Validation -> types.Validation)
 func ValidationFromString(raw, uuid string) (validation types.LulaValidation, err error) {
 	if raw == "" {
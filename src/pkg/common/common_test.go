@@ -13,7 +13,17 @@ import (
 
 	"github.com/mike-winberry/lulalib/src/pkg/common"
 	"github.com/mike-winberry/lulalib/src/pkg/domains/api"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/command"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/git"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/host"
 	kube "github.com/mike-winberry/lulalib/src/pkg/domains/kubernetes"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/logs"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/oci"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/prometheus"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sbom"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sql"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/terraform"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/tls"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/kyverno"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/opa"
 )
@@ -109,6 +119,251 @@ func TestGetDomain(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "valid command domain",
+			domain: common.Domain{
+				Type: "command",
+				CommandSpec: &command.CommandSpec{
+					Commands: []command.Command{
+						{
+							Name:    "kernel",
+							Command: "uname",
+							Args:    []string{"-r"},
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "command.CommandDomain",
+		},
+		{
+			name: "invalid command domain",
+			domain: common.Domain{
+				Type:        "command",
+				CommandSpec: &command.CommandSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid host domain",
+			domain: common.Domain{
+				Type: "host",
+				HostSpec: &host.HostSpec{
+					Facts: []host.Fact{host.FactUsers, host.FactMounts},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "host.HostDomain",
+		},
+		{
+			name: "invalid host domain",
+			domain: common.Domain{
+				Type: "host",
+				HostSpec: &host.HostSpec{
+					Facts: []host.Fact{"processes"},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid oci domain",
+			domain: common.Domain{
+				Type: "oci",
+				OciSpec: &oci.OciSpec{
+					Images: []oci.Image{
+						{
+							Name:      "app",
+							Reference: "ghcr.io/example/app:1.0.0",
+							Files:     []string{"/etc/passwd"},
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "oci.OciDomain",
+		},
+		{
+			name: "invalid oci domain",
+			domain: common.Domain{
+				Type:    "oci",
+				OciSpec: &oci.OciSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid sbom domain",
+			domain: common.Domain{
+				Type: "sbom",
+				SbomSpec: &sbom.SbomSpec{
+					Sboms: []sbom.Sbom{
+						{
+							Name: "app",
+							Path: "sbom.spdx.json",
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "sbom.SbomDomain",
+		},
+		{
+			name: "invalid sbom domain",
+			domain: common.Domain{
+				Type:     "sbom",
+				SbomSpec: &sbom.SbomSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid terraform domain",
+			domain: common.Domain{
+				Type: "terraform",
+				TerraformSpec: &terraform.TerraformSpec{
+					Files: []terraform.TerraformFile{
+						{
+							Name: "plan",
+							Path: "plan.json",
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "terraform.TerraformDomain",
+		},
+		{
+			name: "invalid terraform domain",
+			domain: common.Domain{
+				Type:          "terraform",
+				TerraformSpec: &terraform.TerraformSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid tls domain",
+			domain: common.Domain{
+				Type: "tls",
+				TlsSpec: &tls.TlsSpec{
+					Targets: []tls.Target{
+						{
+							Name:    "website",
+							Address: "example.com:443",
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "tls.TlsDomain",
+		},
+		{
+			name: "invalid tls domain",
+			domain: common.Domain{
+				Type:    "tls",
+				TlsSpec: &tls.TlsSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid sql domain",
+			domain: common.Domain{
+				Type: "sql",
+				SqlSpec: &sql.SqlSpec{
+					Driver: sql.DriverPostgres,
+					DsnEnv: "APP_DATABASE_DSN",
+					Queries: []sql.Query{
+						{
+							Name:  "roles",
+							Query: "SELECT rolname FROM pg_roles",
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "sql.SqlDomain",
+		},
+		{
+			name: "invalid sql domain",
+			domain: common.Domain{
+				Type:    "sql",
+				SqlSpec: &sql.SqlSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid git domain",
+			domain: common.Domain{
+				Type: "git",
+				GitSpec: &git.GitSpec{
+					Repositories: []git.Repository{
+						{
+							Name:   "repo",
+							Source: "git::https://github.com/org/repo.git",
+							Files:  []string{"CODEOWNERS"},
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "git.GitDomain",
+		},
+		{
+			name: "invalid git domain",
+			domain: common.Domain{
+				Type:    "git",
+				GitSpec: &git.GitSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid prometheus domain",
+			domain: common.Domain{
+				Type: "prometheus",
+				PrometheusSpec: &prometheus.PrometheusSpec{
+					Url: "http://prometheus:9090",
+					Queries: []prometheus.Query{
+						{
+							Name:  "up",
+							Query: `up{job="app"}`,
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "prometheus.PrometheusDomain",
+		},
+		{
+			name: "invalid prometheus domain",
+			domain: common.Domain{
+				Type:           "prometheus",
+				PrometheusSpec: &prometheus.PrometheusSpec{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid logs domain",
+			domain: common.Domain{
+				Type: "logs",
+				LogsSpec: &logs.LogsSpec{
+					Logs: []logs.Log{
+						{
+							Name:   "auth",
+							Path:   "/var/log/auth.log",
+							Format: logs.FormatSyslog3164,
+							Tail:   1000,
+						},
+					},
+				},
+			},
+			expectedErr:    false,
+			expectedDomain: "logs.LogsDomain",
+		},
+		{
+			name: "invalid logs domain",
+			domain: common.Domain{
+				Type:     "logs",
+				LogsSpec: &logs.LogsSpec{},
+			},
+			expectedErr: true,
+		},
 		{
 			name: "invalid type domain",
 			domain: common.Domain{
@@ -134,6 +389,46 @@ func TestGetDomain(t *testing.T) {
 				if _, ok := result.(api.ApiDomain); !ok {
 					t.Errorf("Expected result to be api.ApiDomain, got %T", result)
 				}
+			case "command.CommandDomain":
+				if _, ok := result.(command.CommandDomain); !ok {
+					t.Errorf("Expected result to be command.CommandDomain, got %T", result)
+				}
+			case "host.HostDomain":
+				if _, ok := result.(host.HostDomain); !ok {
+					t.Errorf("Expected result to be host.HostDomain, got %T", result)
+				}
+			case "oci.OciDomain":
+				if _, ok := result.(oci.OciDomain); !ok {
+					t.Errorf("Expected result to be oci.OciDomain, got %T", result)
+				}
+			case "sbom.SbomDomain":
+				if _, ok := result.(sbom.SbomDomain); !ok {
+					t.Errorf("Expected result to be sbom.SbomDomain, got %T", result)
+				}
+			case "terraform.TerraformDomain":
+				if _, ok := result.(terraform.TerraformDomain); !ok {
+					t.Errorf("Expected result to be terraform.TerraformDomain, got %T", result)
+				}
+			case "tls.TlsDomain":
+				if _, ok := result.(tls.TlsDomain); !ok {
+					t.Errorf("Expected result to be tls.TlsDomain, got %T", result)
+				}
+			case "sql.SqlDomain":
+				if _, ok := result.(sql.SqlDomain); !ok {
+					t.Errorf("Expected result to be sql.SqlDomain, got %T", result)
+				}
+			case "git.GitDomain":
+				if _, ok := result.(git.GitDomain); !ok {
+					t.Errorf("Expected result to be git.GitDomain, got %T", result)
+				}
+			case "prometheus.PrometheusDomain":
+				if _, ok := result.(prometheus.PrometheusDomain); !ok {
+					t.Errorf("Expected result to be prometheus.PrometheusDomain, got %T", result)
+				}
+			case "logs.LogsDomain":
+				if _, ok := result.(logs.LogsDomain); !ok {
+					t.Errorf("Expected result to be logs.LogsDomain, got %T", result)
+				}
 			case "nil":
 				if result != nil {
 					t.Errorf("Expected result to be nil, got %T", result)
@@ -43,3 +43,27 @@ func DownloadFile(ctx context.Context, dst, src, wd string) (string, error) {
 
 	return rsp.Dst, err
 }
+
+// DownloadDir downloads a directory, such as a git repository or an archive, to
+// dst from src using any of the go-getter protocols supported by DownloadFile.
+// wd is used when resolving relative paths. DownloadDir returns the actual
+// download path and error.
+func DownloadDir(ctx context.Context, dst, src, wd string) (string, error) {
+	client := getter.Client{
+		// following symlinks is a security concern
+		DisableSymlinks: true,
+	}
+
+	rsp, err := client.Get(ctx, &getter.Request{
+		Src:     src,
+		Dst:     dst,
+		Pwd:     wd,
+		Copy:    true, // if we are getting a local directory, copy it instead of making a symlink
+		GetMode: getter.ModeDir,
+	})
+	if rsp == nil {
+		return "", err
+	}
+
+	return rsp.Dst, err
+}
@@ -142,18 +142,28 @@ func Fetch(inputURL string, opts ...FetchOption) (bytes []byte, err error) {
 // If the URL scheme is not "file", an error is returned.
 // If the URL is relative, the component definition directory is prepended if set, otherwise the current working directory is prepended.
 func FetchLocalFile(url *url.URL, config *fetchOpts) ([]byte, error) {
+	path, err := LocalFilePath(url, config.baseDir)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(path)
+	return bytes, err
+}
+
+// LocalFilePath returns the path of a local file from a given URL, as it is read by FetchLocalFile.
+// If the URL scheme is not "file", an error is returned.
+// If the URL is relative, the base directory is prepended.
+func LocalFilePath(url *url.URL, baseDir string) (string, error) {
 	if url.Scheme != "file" {
-		return nil, errors.New("expected file URL scheme")
+		return "", errors.New("expected file URL scheme")
 	}
 	requestUri := url.RequestURI()
 
 	// If the request uri is absolute, use it directly
 	if _, err := os.Stat(requestUri); err != nil {
-		requestUri = filepath.Join(config.baseDir, url.Host, requestUri)
+		requestUri = filepath.Join(baseDir, url.Host, requestUri)
 	}
-	requestUri = filepath.Clean(requestUri)
-	bytes, err := os.ReadFile(requestUri)
-	return bytes, err
+	return filepath.Clean(requestUri), nil
 }
 
 // GetLocalFileDir returns the directory of a local file
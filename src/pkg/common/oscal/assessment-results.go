@@ -1,6 +1,7 @@
 package oscal
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -358,6 +359,9 @@ func CreateObservation(method string, relevantEvidence *[]oscalTypes.RelevantEvi
 				Value: common.AddIdPrefix(validation.UUID),
 			},
 		}
+		if validation.Result != nil {
+			*observation.Props = append(*observation.Props, observationDataProps(validation.Result.ObservationData)...)
+		}
 	}
 	if resourcesHref != "" {
 		observation.Links = &[]oscalTypes.Link{
@@ -370,6 +374,30 @@ func CreateObservation(method string, relevantEvidence *[]oscalTypes.RelevantEvi
 	return observation
 }
 
+// observationDataProps returns a property for each structured observation, with the observation
+// as a JSON object of its key and value
+func observationDataProps(data map[string]interface{}) []oscalTypes.Property {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	props := make([]oscalTypes.Property, 0, len(keys))
+	for _, key := range keys {
+		value, err := json.Marshal(map[string]interface{}{key: data[key]})
+		if err != nil {
+			continue
+		}
+		props = append(props, oscalTypes.Property{
+			Name:  "observation-data",
+			Ns:    LULA_NAMESPACE,
+			Value: string(value),
+		})
+	}
+	return props
+}
+
 // Creates a result from findings and observations
 func CreateResult(findingMap map[string]oscalTypes.Finding, observations []oscalTypes.Observation) (oscalTypes.Result, error) {
 
@@ -15,8 +15,20 @@ import (
 	"github.com/mike-winberry/lulalib/src/config"
 	"github.com/mike-winberry/lulalib/src/pkg/common/schemas"
 	"github.com/mike-winberry/lulalib/src/pkg/domains/api"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/command"
 	"github.com/mike-winberry/lulalib/src/pkg/domains/files"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/git"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/host"
 	kube "github.com/mike-winberry/lulalib/src/pkg/domains/kubernetes"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/logs"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/oci"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/prometheus"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sbom"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/sql"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/terraform"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/tls"
+	"github.com/mike-winberry/lulalib/src/pkg/providers/cel"
+	"github.com/mike-winberry/lulalib/src/pkg/providers/jsonschema"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/kyverno"
 	"github.com/mike-winberry/lulalib/src/pkg/providers/opa"
 	"github.com/mike-winberry/lulalib/src/types"
@@ -37,7 +49,11 @@ type Validation struct {
 	LulaVersion string                      `json:"lula-version" yaml:"lula-version"`
 	Metadata    *Metadata                   `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	Provider    *Provider                   `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Providers   []NamedProvider             `json:"providers,omitempty" yaml:"providers,omitempty"`
+	Combine     string                      `json:"combine,omitempty" yaml:"combine,omitempty"`
+	Threshold   int                         `json:"threshold,omitempty" yaml:"threshold,omitempty"`
 	Domain      *Domain                     `json:"domain,omitempty" yaml:"domain,omitempty"`
+	Domains     []NamedDomain               `json:"domains,omitempty" yaml:"domains,omitempty"`
 	Tests       *[]types.LulaValidationTest `json:"tests,omitempty" yaml:"tests,omitempty"`
 }
 
@@ -75,6 +91,11 @@ func (v *Validation) ToResource() (resource *oscalTypes.Resource, err error) {
 			v.Provider.OpaSpec.Rego = CleanMultilineString(v.Provider.OpaSpec.Rego)
 		}
 	}
+	for _, p := range v.Providers {
+		if p.OpaSpec != nil {
+			p.OpaSpec.Rego = CleanMultilineString(p.OpaSpec.Rego)
+		}
+	}
 
 	validationBytes, err := v.MarshalYaml()
 	if err != nil {
@@ -96,7 +117,7 @@ type Metadata struct {
 
 // Domain is a structure that contains the domain type and the corresponding spec
 type Domain struct {
-	// Type is the type of domain: enum: kubernetes, api
+	// Type is the type of domain: enum: kubernetes, api, file, command, host, oci, sbom, terraform, tls, sql, git, prometheus, logs
 	Type string `json:"type" yaml:"type"`
 	// KubernetesSpec is the specification for a Kubernetes domain, required if type is kubernetes
 	KubernetesSpec *kube.KubernetesSpec `json:"kubernetes-spec,omitempty" yaml:"kubernetes-spec,omitempty"`
@@ -104,12 +125,50 @@ type Domain struct {
 	ApiSpec *api.ApiSpec `json:"api-spec,omitempty" yaml:"api-spec,omitempty"`
 	// FileSpec is the specification for a File domain, required if type is file
 	FileSpec *files.Spec `json:"file-spec,omitempty" yaml:"file-spec,omitempty"`
+	// CommandSpec is the specification for a Command domain, required if type is command
+	CommandSpec *command.CommandSpec `json:"command-spec,omitempty" yaml:"command-spec,omitempty"`
+	// HostSpec is the specification for a Host domain, required if type is host
+	HostSpec *host.HostSpec `json:"host-spec,omitempty" yaml:"host-spec,omitempty"`
+	// OciSpec is the specification for an OCI domain, required if type is oci
+	OciSpec *oci.OciSpec `json:"oci-spec,omitempty" yaml:"oci-spec,omitempty"`
+	// SbomSpec is the specification for an SBOM domain, required if type is sbom
+	SbomSpec *sbom.SbomSpec `json:"sbom-spec,omitempty" yaml:"sbom-spec,omitempty"`
+	// TerraformSpec is the specification for a Terraform domain, required if type is terraform
+	TerraformSpec *terraform.TerraformSpec `json:"terraform-spec,omitempty" yaml:"terraform-spec,omitempty"`
+	// TlsSpec is the specification for a TLS domain, required if type is tls
+	TlsSpec *tls.TlsSpec `json:"tls-spec,omitempty" yaml:"tls-spec,omitempty"`
+	// SqlSpec is the specification for a SQL domain, required if type is sql
+	SqlSpec *sql.SqlSpec `json:"sql-spec,omitempty" yaml:"sql-spec,omitempty"`
+	// GitSpec is the specification for a git domain, required if type is git
+	GitSpec *git.GitSpec `json:"git-spec,omitempty" yaml:"git-spec,omitempty"`
+	// PrometheusSpec is the specification for a Prometheus domain, required if type is prometheus
+	PrometheusSpec *prometheus.PrometheusSpec `json:"prometheus-spec,omitempty" yaml:"prometheus-spec,omitempty"`
+	// LogsSpec is the specification for a logs domain, required if type is logs
+	LogsSpec *logs.LogsSpec `json:"logs-spec,omitempty" yaml:"logs-spec,omitempty"`
+}
+
+// NamedDomain is one of the domains of a validation with multiple domains. Its resources are
+// provided under its name.
+type NamedDomain struct {
+	// Name is the key of the domain resources
+	Name   string `json:"name" yaml:"name"`
+	Domain `json:",inline" yaml:",inline"`
 }
 
 type Provider struct {
-	Type        string               `json:"type" yaml:"type"`
-	OpaSpec     *opa.OpaSpec         `json:"opa-spec,omitempty" yaml:"opa-spec,omitempty"`
-	KyvernoSpec *kyverno.KyvernoSpec `json:"kyverno-spec,omitempty" yaml:"kyverno-spec,omitempty"`
+	Type           string                     `json:"type" yaml:"type"`
+	OpaSpec        *opa.OpaSpec               `json:"opa-spec,omitempty" yaml:"opa-spec,omitempty"`
+	KyvernoSpec    *kyverno.KyvernoSpec       `json:"kyverno-spec,omitempty" yaml:"kyverno-spec,omitempty"`
+	CelSpec        *cel.CelSpec               `json:"cel-spec,omitempty" yaml:"cel-spec,omitempty"`
+	JsonSchemaSpec *jsonschema.JsonSchemaSpec `json:"jsonschema-spec,omitempty" yaml:"jsonschema-spec,omitempty"`
+}
+
+// NamedProvider is one of the providers of a validation with multiple providers. Its observations
+// are prefixed by its name.
+type NamedProvider struct {
+	// Name is the prefix of the provider observations
+	Name     string `json:"name" yaml:"name"`
+	Provider `json:",inline" yaml:",inline"`
 }
 
 // Lint is a convenience method to lint a Validation object
@@ -134,6 +193,17 @@ func (validation *Validation) ToLulaValidation(uuid string) (lulaValidation type
 		versionConstraint = validation.LulaVersion
 	}
 
+	// The schema rejects these combinations, but its errors do not say which fields conflict
+	if validation.Domain != nil && len(validation.Domains) > 0 {
+		return lulaValidation, fmt.Errorf("%w: only one of domain or domains can be specified", ErrInvalidDomain)
+	}
+	if validation.Provider != nil && len(validation.Providers) > 0 {
+		return lulaValidation, fmt.Errorf("%w: only one of provider or providers can be specified", ErrInvalidProvider)
+	}
+	if len(validation.Providers) == 0 && (validation.Combine != "" || validation.Threshold != 0) {
+		return lulaValidation, fmt.Errorf("%w: combine and threshold can only be specified with providers", ErrInvalidProvider)
+	}
+
 	lintResult := validation.Lint()
 	// If the validation is not valid, return the error
 	if oscalValidation.IsNonSchemaValidationError(&lintResult) {
@@ -153,20 +223,36 @@ func (validation *Validation) ToLulaValidation(uuid string) (lulaValidation type
 	// TODO: Is there a better location for context?
 	ctx := context.Background()
 
-	domain, err := GetDomain(validation.Domain)
-	if domain == nil {
-		return lulaValidation, fmt.Errorf("%w: %s", ErrInvalidDomain, validation.Domain.Type)
-	}
-	if err != nil {
-		return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+	var domain types.Domain
+	if len(validation.Domains) > 0 {
+		domain, err = GetMultiDomain(validation.Domains)
+		if err != nil {
+			return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+		}
+	} else {
+		domain, err = GetDomain(validation.Domain)
+		if domain == nil {
+			return lulaValidation, fmt.Errorf("%w: %s", ErrInvalidDomain, validation.Domain.Type)
+		}
+		if err != nil {
+			return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+		}
 	}
 	lulaValidation.Domain = &domain
 
-	provider, err := GetProvider(validation.Provider, ctx)
-	if provider == nil {
-		return lulaValidation, fmt.Errorf("%w: %s", ErrInvalidProvider, validation.Provider.Type)
-	} else if err != nil {
-		return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	var provider types.Provider
+	if len(validation.Providers) > 0 {
+		provider, err = GetMultiProvider(validation.Providers, validation.Combine, validation.Threshold, ctx)
+		if err != nil {
+			return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+		}
+	} else {
+		provider, err = GetProvider(validation.Provider, ctx)
+		if provider == nil {
+			return lulaValidation, fmt.Errorf("%w: %s", ErrInvalidProvider, validation.Provider.Type)
+		} else if err != nil {
+			return lulaValidation, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+		}
 	}
 	lulaValidation.Provider = &provider
 
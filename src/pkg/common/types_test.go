@@ -1,11 +1,16 @@
 package common_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/mike-winberry/lulalib/src/config"
 	"github.com/mike-winberry/lulalib/src/pkg/common"
+	"github.com/mike-winberry/lulalib/src/pkg/domains/multi"
+	"github.com/mike-winberry/lulalib/src/types"
 )
 
 func TestToLulaValidation(t *testing.T) {
@@ -35,6 +40,233 @@ provider:
 `),
 			expectErr: false,
 		},
+		{
+			name: "Valid multiple domains",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-valid-domains"
+domains:
+  - name: cluster
+    type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+  - name: cmdb
+    type: "api"
+    api-spec:
+      requests:
+        - name: namespaces
+          url: "https://cmdb.example.com/namespaces"
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate\n\ndefault validate = false"
+`),
+			expectErr: false,
+		},
+		{
+			name: "Invalid domain, domain and domains",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-invalid-domains"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+domains:
+  - name: cluster
+    type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate\n\ndefault validate = false"
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidDomain,
+		},
+		{
+			name: "Invalid schema, domains missing name",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-invalid-domains"
+domains:
+  - type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate\n\ndefault validate = false"
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidSchema,
+		},
+		{
+			name: "Invalid domains, duplicate name",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-invalid-domains"
+domains:
+  - name: cluster
+    type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+  - name: cluster
+    type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate\n\ndefault validate = false"
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidDomain,
+		},
+		{
+			name: "Valid multiple providers",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+providers:
+  - name: first
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = false"
+  - name: second
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = true"
+combine: "threshold"
+threshold: 1
+`),
+			expectErr: false,
+		},
+		{
+			name: "Invalid provider, provider and providers",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+providers:
+  - name: first
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = false"
+  - name: second
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = true"
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate"
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidProvider,
+		},
+		{
+			name: "Invalid schema, threshold without combine threshold",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+providers:
+  - name: first
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = false"
+  - name: second
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = true"
+combine: "any"
+threshold: 1
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidSchema,
+		},
+		{
+			name: "Invalid provider, combine without providers",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate"
+combine: "any"
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidProvider,
+		},
+		{
+			name: "Invalid provider, threshold without providers",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate"
+threshold: 1
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidProvider,
+		},
+		{
+			name: "Invalid providers, threshold above number of providers",
+			inputYaml: []byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+providers:
+  - name: first
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = false"
+  - name: second
+    type: "opa"
+    opa-spec:
+      rego: "package validate\n\ndefault validate = true"
+combine: "threshold"
+threshold: 3
+`),
+			expectErr:       true,
+			expectedErrType: common.ErrInvalidProvider,
+		},
 		{
 			name: "Invalid version",
 			inputYaml: []byte(`
@@ -231,3 +463,99 @@ tests:
 		})
 	}
 }
+
+func TestToLulaValidationMultipleDomains(t *testing.T) {
+	config.CLIVersion = "1.0.0" // Set the version for testing purposes
+
+	var validation common.Validation
+	err := validation.UnmarshalYaml([]byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-domains"
+domains:
+  - name: cluster
+    type: "kubernetes"
+    kubernetes-spec:
+      resources: []
+  - name: inventory
+    type: "command"
+    command-spec:
+      commands:
+        - name: hosts
+          command: "cat"
+          args: ["inventory.json"]
+provider:
+  type: "opa"
+  opa-spec:
+    rego: "package validate\n\ndefault validate = false"
+`))
+	require.NoError(t, err)
+
+	lulaValidation, err := validation.ToLulaValidation("")
+	require.NoError(t, err)
+
+	domain, ok := (*lulaValidation.Domain).(multi.MultiDomain)
+	require.True(t, ok, "expected multi.MultiDomain, got %T", *lulaValidation.Domain)
+	require.Len(t, domain.Domains, 2)
+	require.Equal(t, "cluster", domain.Domains[0].Name)
+	require.Equal(t, "inventory", domain.Domains[1].Name)
+	// the command domain makes the validation executable
+	require.True(t, domain.IsExecutable())
+}
+
+func TestToLulaValidationMultipleProviders(t *testing.T) {
+	config.CLIVersion = "1.0.0" // Set the version for testing purposes
+
+	var validation common.Validation
+	err := validation.UnmarshalYaml([]byte(`
+lula-version: "1.0.0"
+metadata:
+  name: "test-providers"
+domain:
+  type: "kubernetes"
+  kubernetes-spec:
+    resources: []
+providers:
+  - name: labeled
+    type: "opa"
+    opa-spec:
+      rego: |
+        package validate
+
+        default validate = false
+
+        validate {
+          input.pod.metadata.labels.foo == "bar"
+        }
+  - name: annotated
+    type: "opa"
+    opa-spec:
+      rego: |
+        package validate
+
+        default validate = false
+
+        validate {
+          input.pod.metadata.annotations.foo == "bar"
+        }
+combine: "any"
+`))
+	require.NoError(t, err)
+
+	lulaValidation, err := validation.ToLulaValidation("")
+	require.NoError(t, err)
+
+	resources := types.DomainResources{
+		"pod": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"foo": "bar"},
+			},
+		},
+	}
+	err = lulaValidation.Validate(context.Background(), types.WithStaticResources(resources))
+	require.NoError(t, err)
+	require.Equal(t, 1, lulaValidation.Result.Passing)
+	require.Equal(t, 0, lulaValidation.Result.Failing)
+	require.Equal(t, "satisfied (1 passing, 0 failing)", lulaValidation.Result.Observations["labeled"])
+	require.Equal(t, "not-satisfied (0 passing, 1 failing)", lulaValidation.Result.Observations["annotated"])
+}
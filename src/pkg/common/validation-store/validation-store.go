@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/defenseunicorns/go-oscal/src/pkg/files"
 	"github.com/defenseunicorns/go-oscal/src/pkg/uuid"
@@ -137,15 +139,10 @@ func (v *ValidationStore) RunValidations(ctx context.Context, confirmExecution,
 			}
 
 			// Add the observation to the observation map
-			var remarks string
-			if len(val.Result.Observations) > 0 {
-				for k, v := range val.Result.Observations {
-					remarks += fmt.Sprintf("%s: %s\n", k, v)
-				}
-			}
+			remarks := formatRemarks(val.Result.Observations)
 
 			// Save Resources if specified
-			var resourceHref string
+			var resourceHref, observationsHref string
 			if saveResources {
 				resourceUuid := uuid.NewUUID()
 				// Create a remote resource file -> create directory 'resources' in the assessment-results directory -> create file with UUID as name
@@ -161,6 +158,16 @@ func (v *ValidationStore) RunValidations(ctx context.Context, confirmExecution,
 					message.Debugf("Error writing remote resource file: %v", err)
 				}
 				resourceHref = fmt.Sprintf("file://./resources/%s", filename)
+
+				if len(val.Result.ObservationData) > 0 {
+					observationsFilename := fmt.Sprintf("%s-observations.json", resourceUuid)
+					err = files.WriteOutput([]byte(message.JSONValue(val.Result.ObservationData)), filepath.Join(outputsDir, "resources", observationsFilename))
+					if err != nil {
+						message.Debugf("Error writing observation data file: %v", err)
+					} else {
+						observationsHref = fmt.Sprintf("file://./resources/%s", observationsFilename)
+					}
+				}
 			}
 
 			// Create an observation
@@ -171,6 +178,12 @@ func (v *ValidationStore) RunValidations(ctx context.Context, confirmExecution,
 				},
 			}
 			observation := oscal.CreateObservation("TEST", relevantEvidence, val, resourceHref, "[TEST]: %s - %s\n", k, val.Name)
+			if observationsHref != "" {
+				*observation.Links = append(*observation.Links, oscalTypes.Link{
+					Href: observationsHref,
+					Rel:  "lula.observations",
+				})
+			}
 			v.observationMap[k] = &observation
 			observations = append(observations, observation)
 
@@ -239,3 +252,24 @@ func (v *ValidationStore) RunTests(ctx context.Context) map[string]types.LulaVal
 	}
 	return testReportMap
 }
+
+// formatRemarks returns the observations sorted by key, one per line. Observations that span
+// multiple lines, such as structured observations, start on the line after their key and are indented.
+func formatRemarks(observations map[string]string) string {
+	keys := make([]string, 0, len(observations))
+	for k := range observations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var remarks strings.Builder
+	for _, k := range keys {
+		v := observations[k]
+		if strings.Contains(v, "\n") {
+			remarks.WriteString(fmt.Sprintf("%s:\n  %s\n", k, strings.ReplaceAll(v, "\n", "\n  ")))
+		} else {
+			remarks.WriteString(fmt.Sprintf("%s: %s\n", k, v))
+		}
+	}
+	return remarks.String()
+}
@@ -2,6 +2,9 @@ package validationstore_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/defenseunicorns/go-oscal/src/pkg/uuid"
@@ -195,6 +198,42 @@ func TestRunValidations(t *testing.T) {
 	})
 }
 
+func TestRunValidationsStructuredObservations(t *testing.T) {
+	message.NoProgress = true
+	validation := types.CreatePassingLulaValidation("structured-validation")
+	validation.Result.AddObservation("validate.msg", "pods checked")
+	validation.Result.AddObservation("validate.violations", []interface{}{"pod a is privileged", "pod b is privileged"})
+
+	outputsDir := t.TempDir()
+	v := validationstore.NewValidationStore()
+	v.AddLulaValidation(validation, uuid.NewUUID())
+	observations := v.RunValidations(context.Background(), true, true, outputsDir)
+	require.Len(t, observations, 1)
+	observation := observations[0]
+
+	require.Equal(t, "validate.msg: pods checked\nvalidate.violations:\n  - pod a is privileged\n  - pod b is privileged\n", (*observation.RelevantEvidence)[0].Remarks)
+
+	require.NotNil(t, observation.Props)
+	require.Contains(t, *observation.Props, oscalTypes.Property{
+		Name:  "observation-data",
+		Ns:    "https://docs.lula.dev/oscal/ns",
+		Value: `{"validate.violations":["pod a is privileged","pod b is privileged"]}`,
+	})
+
+	require.NotNil(t, observation.Links)
+	var observationsHref string
+	for _, link := range *observation.Links {
+		if link.Rel == "lula.observations" {
+			observationsHref = link.Href
+		}
+	}
+	require.NotEmpty(t, observationsHref)
+
+	data, err := os.ReadFile(filepath.Join(outputsDir, strings.TrimPrefix(observationsHref, "file://./")))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"validate.violations": ["pod a is privileged", "pod b is privileged"]}`, string(data))
+}
+
 func TestGetRelatedObservation(t *testing.T) {
 	message.NoProgress = true
 	validationPass := types.CreatePassingLulaValidation("passing-validation")
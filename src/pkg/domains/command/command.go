@@ -0,0 +1,215 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/mike-winberry/lulalib/src/pkg/message"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// waitDelay is how long to wait for the output of a command to be closed after it has exited or been killed
+const waitDelay = 2 * time.Second
+
+// CommandResult is the evidence captured from a single command
+type CommandResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Output   any
+}
+
+func (c CommandDomain) runCommands(ctx context.Context) (types.DomainResources, error) {
+	collection := make(types.DomainResources, len(c.commands))
+	var errs error
+	for _, cmd := range c.commands {
+		result, err := runCommand(ctx, cmd)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("command %s: %w", cmd.name, err))
+		}
+		collection[cmd.name] = types.DomainResources{
+			"exitcode": result.ExitCode,
+			"stdout":   result.Stdout,
+			"stderr":   result.Stderr,
+			"output":   result.Output,
+		}
+	}
+	return collection, errs
+}
+
+// runCommand executes the command without a shell and parses its stdout. A non-zero exit code
+// is recorded in the result and is not an error; failing to start or being killed by the
+// timeout is.
+func runCommand(ctx context.Context, cmd command) (CommandResult, error) {
+	result := CommandResult{ExitCode: -1}
+
+	runCtx, cancel := context.WithTimeout(ctx, cmd.timeout)
+	defer cancel()
+
+	execCmd := exec.CommandContext(runCtx, cmd.path, cmd.args...) // #nosec G204 -- execution is gated by the executable confirmation
+	execCmd.Env = cmd.env
+	execCmd.Dir = workingDir(ctx, cmd.workingDir)
+	stdout := &limitedBuffer{limit: cmd.maxOutput}
+	stderr := &limitedBuffer{limit: cmd.maxOutput}
+	execCmd.Stdout = stdout
+	execCmd.Stderr = stderr
+	// Run the command in its own process group, so that the timeout also kills any processes it starts
+	execCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	execCmd.Cancel = func() error {
+		return killProcessGroup(execCmd.Process.Pid)
+	}
+	// Stop waiting for output that is held open by a process started by the command, once the command
+	// has exited or been killed
+	execCmd.WaitDelay = waitDelay
+
+	message.Debugf("running command %s: %s %s", cmd.name, cmd.path, strings.Join(cmd.args, " "))
+	err := execCmd.Run()
+	if execCmd.Process != nil {
+		// Processes left running in the background by the command are not part of its evidence
+		_ = killProcessGroup(execCmd.Process.Pid)
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	if stdout.truncated || stderr.truncated {
+		message.Debugf("command %s output truncated to %d bytes", cmd.name, cmd.maxOutput)
+	}
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if runCtx.Err() == context.DeadlineExceeded {
+			return result, fmt.Errorf("timed out after %s", cmd.timeout)
+		} else if errors.Is(err, exec.ErrWaitDelay) {
+			// The command exited, but a process it started kept its output open
+			message.Debugf("command %s exited with output held open by another process", cmd.name)
+			result.ExitCode = execCmd.ProcessState.ExitCode()
+		} else if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			return result, err
+		}
+	} else {
+		result.ExitCode = 0
+	}
+
+	output, err := parseOutput(result.Stdout, cmd.parser, cmd.separator)
+	if err != nil {
+		// A failed command often writes nothing, or an error, to stdout; its exit code and stderr are the evidence
+		if result.ExitCode != 0 {
+			message.Debugf("command %s exited with %d and its output could not be parsed as %s: %v", cmd.name, result.ExitCode, cmd.parser, err)
+			return result, nil
+		}
+		return result, fmt.Errorf("error parsing output as %s: %w", cmd.parser, err)
+	}
+	result.Output = output
+	return result, nil
+}
+
+// killProcessGroup kills every process in the process group of the command
+func killProcessGroup(pid int) error {
+	err := syscall.Kill(-pid, syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// workingDir resolves a relative working directory against the directory of the validation
+func workingDir(ctx context.Context, dir string) string {
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+	return filepath.Join(workDir, dir)
+}
+
+// parseOutput converts the command stdout into structured data using the parser
+func parseOutput(stdout string, parser Parser, separator string) (any, error) {
+	switch parser {
+	case ParserJSON:
+		var output any
+		if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+			return nil, err
+		}
+		return output, nil
+	case ParserYAML:
+		var output any
+		if err := yaml.Unmarshal([]byte(stdout), &output); err != nil {
+			return nil, err
+		}
+		return output, nil
+	case ParserLines:
+		lines := splitLines(stdout)
+		output := make([]any, len(lines))
+		for i, line := range lines {
+			output[i] = line
+		}
+		return output, nil
+	case ParserKeyValue:
+		output := make(map[string]any)
+		for _, line := range splitLines(stdout) {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, found := strings.Cut(line, separator)
+			if !found {
+				return nil, fmt.Errorf("line %q does not contain separator %q", line, separator)
+			}
+			output[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return output, nil
+	default:
+		return stdout, nil
+	}
+}
+
+// splitLines returns each line of the output, without the trailing newline
+func splitLines(s string) []string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(s)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if remaining <= 0 {
+		b.truncated = b.truncated || len(p) > 0
+		return len(p), nil
+	}
+	if len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
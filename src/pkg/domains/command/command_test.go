@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*CommandDomain)(nil)
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	maxOutput := 5
+	domain, err := CreateCommandDomain(&CommandSpec{
+		Commands: []Command{
+			{Name: "string", Command: "echo", Args: []string{"hello"}},
+			{Name: "json", Command: "echo", Args: []string{`{"enabled": true}`}, Parser: ParserJSON},
+			{Name: "yaml", Command: "printf", Args: []string{"a: 1\nb: [x]\n"}, Parser: ParserYAML},
+			{Name: "lines", Command: "printf", Args: []string{"one\ntwo\n"}, Parser: ParserLines},
+			{Name: "key-value", Command: "printf", Args: []string{"# comment\nnet.ipv4.ip_forward = 0\nkernel.randomize_va_space=2\n"}, Parser: ParserKeyValue},
+			{Name: "separator", Command: "printf", Args: []string{"permitrootlogin no\nport 22\n"}, Parser: ParserKeyValue, Separator: " "},
+			{Name: "exit", Command: "sh", Args: []string{"-c", "echo oops >&2; exit 3"}},
+			{Name: "exit-json", Command: "sh", Args: []string{"-c", "echo denied >&2; exit 2"}, Parser: ParserJSON},
+			{Name: "env", Command: "sh", Args: []string{"-c", "echo $FOO"}, Env: map[string]string{"FOO": "bar"}},
+			{Name: "truncated", Command: "echo", Args: []string{"0123456789"}, Options: &CommandOpts{MaxOutputBytes: &maxOutput}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, domain.IsExecutable())
+
+	resources, err := domain.GetResources(context.Background())
+	require.NoError(t, err)
+
+	output := func(name string) any {
+		return resources[name].(types.DomainResources)["output"]
+	}
+
+	require.Equal(t, "hello\n", output("string"))
+	require.Equal(t, map[string]any{"enabled": true}, output("json"))
+	require.Equal(t, map[string]any{"a": float64(1), "b": []any{"x"}}, output("yaml"))
+	require.Equal(t, []any{"one", "two"}, output("lines"))
+	if diff := cmp.Diff(map[string]any{"net.ipv4.ip_forward": "0", "kernel.randomize_va_space": "2"}, output("key-value")); diff != "" {
+		t.Fatalf("wrong key-value output:\n%s", diff)
+	}
+	require.Equal(t, map[string]any{"permitrootlogin": "no", "port": "22"}, output("separator"))
+	require.Equal(t, "bar\n", output("env"))
+	require.Equal(t, "01234", output("truncated"))
+
+	exit := resources["exit"].(types.DomainResources)
+	require.Equal(t, 3, exit["exitcode"])
+	require.Equal(t, "oops\n", exit["stderr"])
+
+	// a failed command without parsable output is not an error
+	exitJSON := resources["exit-json"].(types.DomainResources)
+	require.Equal(t, 2, exitJSON["exitcode"])
+	require.Equal(t, "denied\n", exitJSON["stderr"])
+	require.Nil(t, exitJSON["output"])
+}
+
+func TestGetResourcesWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, "sub"), 0o755))
+
+	domain, err := CreateCommandDomain(&CommandSpec{
+		Commands: []Command{
+			{Name: "relative", Command: "pwd", WorkingDir: "sub"},
+			{Name: "absolute", Command: "pwd", WorkingDir: workDir},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, workDir)
+	resources, err := domain.GetResources(ctx)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(workDir, "sub")+"\n", resources["relative"].(types.DomainResources)["output"])
+	require.Equal(t, workDir+"\n", resources["absolute"].(types.DomainResources)["output"])
+}
+
+func TestGetResourcesErrors(t *testing.T) {
+	t.Parallel()
+
+	domain, err := CreateCommandDomain(&CommandSpec{
+		Commands: []Command{
+			{Name: "missing", Command: "lula-command-that-does-not-exist"},
+			{Name: "timeout", Command: "sleep", Args: []string{"5"}, Options: &CommandOpts{Timeout: "100ms"}},
+			{Name: "bad-json", Command: "echo", Args: []string{"not json"}, Parser: ParserJSON},
+			{Name: "ok", Command: "echo", Args: []string{"ok"}},
+		},
+	})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(context.Background())
+	require.Error(t, err)
+	require.Equal(t, -1, resources["missing"].(types.DomainResources)["exitcode"])
+	require.Equal(t, -1, resources["timeout"].(types.DomainResources)["exitcode"])
+	require.Nil(t, resources["bad-json"].(types.DomainResources)["output"])
+	require.Equal(t, "ok\n", resources["ok"].(types.DomainResources)["output"])
+}
+
+func TestGetResourcesBackgroundProcesses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	survived := filepath.Join(dir, "survived")
+	domain, err := CreateCommandDomain(&CommandSpec{
+		Commands: []Command{
+			// the grandchild keeps stdout open after the timeout kills the shell
+			{Name: "timeout", Command: "sh", Args: []string{"-c", "(sleep 1; touch " + survived + ") & sleep 30"}, Options: &CommandOpts{Timeout: "200ms"}},
+			// the shell exits, but the grandchild keeps stdout open
+			{Name: "background", Command: "sh", Args: []string{"-c", "sleep 30 & echo started"}},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resources, err := domain.GetResources(context.Background())
+	require.ErrorContains(t, err, "timed out after 200ms")
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	require.Equal(t, -1, resources["timeout"].(types.DomainResources)["exitcode"])
+	background := resources["background"].(types.DomainResources)
+	require.Equal(t, 0, background["exitcode"])
+	require.Equal(t, "started\n", background["output"])
+
+	// the grandchild was killed with the process group of the command
+	time.Sleep(1500 * time.Millisecond)
+	require.NoFileExists(t, survived)
+}
@@ -0,0 +1,129 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+var (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxOutputBytes = 1024 * 1024
+	defaultSeparator      = "="
+)
+
+// validateAndMutateSpec validates the spec values and applies any defaults or
+// other mutations or normalizations necessary. The original values are not modified.
+// validateAndMutateSpec will validate the entire object and may return multiple
+// errors.
+func validateAndMutateSpec(spec *CommandSpec) (domain CommandDomain, errs error) {
+	if spec == nil {
+		return domain, errors.New("spec is required")
+	}
+	if len(spec.Commands) == 0 {
+		errs = errors.Join(errs, errors.New("some commands must be specified"))
+	}
+
+	names := make(map[string]bool, len(spec.Commands))
+	cmds := make([]command, len(spec.Commands))
+	for i, c := range spec.Commands {
+		if c.Name == "" {
+			errs = errors.Join(errs, errors.New("command name cannot be empty"))
+		} else if names[c.Name] {
+			errs = errors.Join(errs, fmt.Errorf("command name %s must be unique", c.Name))
+		}
+		names[c.Name] = true
+		cmds[i].name = c.Name
+
+		if c.Command == "" {
+			errs = errors.Join(errs, fmt.Errorf("command %s: command cannot be empty", c.Name))
+		}
+		cmds[i].path = c.Command
+		cmds[i].args = c.Args
+		cmds[i].workingDir = c.WorkingDir
+
+		switch c.Parser {
+		case "":
+			cmds[i].parser = DefaultParser
+		case ParserString, ParserJSON, ParserYAML, ParserLines, ParserKeyValue:
+			cmds[i].parser = c.Parser
+		default:
+			errs = errors.Join(errs, fmt.Errorf("command %s: unsupported parser %s", c.Name, c.Parser))
+		}
+
+		cmds[i].separator = c.Separator
+		if cmds[i].separator == "" {
+			cmds[i].separator = defaultSeparator
+		}
+
+		opts, err := mergeOptions(spec.Options, c.Options)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("command %s: %w", c.Name, err))
+		}
+		cmds[i].timeout = opts.timeout
+		cmds[i].maxOutput = opts.maxOutput
+		cmds[i].env = buildEnv(opts.inheritEnv, c.Env)
+	}
+	if len(cmds) > 0 {
+		domain.commands = cmds
+	}
+	return domain, errs
+}
+
+// opts contains the parsed Command Options
+type opts struct {
+	timeout    time.Duration
+	inheritEnv bool
+	maxOutput  int
+}
+
+// mergeOptions applies the command-level options over the spec-level options and the defaults
+func mergeOptions(specOpts, cmdOpts *CommandOpts) (opts, error) {
+	options := opts{
+		timeout:   defaultTimeout,
+		maxOutput: defaultMaxOutputBytes,
+	}
+	var errs error
+	for _, o := range []*CommandOpts{specOpts, cmdOpts} {
+		if o == nil {
+			continue
+		}
+		if o.Timeout != "" {
+			duration, err := time.ParseDuration(o.Timeout)
+			if err != nil || duration <= 0 {
+				errs = errors.Join(errs, fmt.Errorf("invalid timeout string: %s", o.Timeout))
+			} else {
+				options.timeout = duration
+			}
+		}
+		if o.InheritEnv != nil {
+			options.inheritEnv = *o.InheritEnv
+		}
+		if o.MaxOutputBytes != nil {
+			if *o.MaxOutputBytes <= 0 {
+				errs = errors.Join(errs, fmt.Errorf("max-output-bytes must be positive"))
+			} else {
+				options.maxOutput = *o.MaxOutputBytes
+			}
+		}
+	}
+	return options, errs
+}
+
+// buildEnv returns the environment for a command: PATH, or the full environment
+// when inherited, followed by the user-specified variables
+func buildEnv(inheritEnv bool, env map[string]string) []string {
+	var base []string
+	if inheritEnv {
+		base = os.Environ()
+	} else if path, ok := os.LookupEnv("PATH"); ok {
+		base = []string{"PATH=" + path}
+	}
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		base = append(base, fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	return base
+}
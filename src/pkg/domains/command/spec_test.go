@@ -0,0 +1,100 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAndMutateSpec(t *testing.T) {
+	t.Parallel()
+
+	inherit := true
+	tooSmall := 0
+
+	tests := map[string]struct {
+		spec        *CommandSpec
+		expectedErr bool
+	}{
+		"nil spec": {
+			spec:        nil,
+			expectedErr: true,
+		},
+		"empty commands": {
+			spec:        &CommandSpec{Commands: []Command{}},
+			expectedErr: true,
+		},
+		"no name": {
+			spec:        &CommandSpec{Commands: []Command{{Command: "echo"}}},
+			expectedErr: true,
+		},
+		"no command": {
+			spec:        &CommandSpec{Commands: []Command{{Name: "test"}}},
+			expectedErr: true,
+		},
+		"duplicate names": {
+			spec:        &CommandSpec{Commands: []Command{{Name: "test", Command: "echo"}, {Name: "test", Command: "echo"}}},
+			expectedErr: true,
+		},
+		"invalid parser": {
+			spec:        &CommandSpec{Commands: []Command{{Name: "test", Command: "echo", Parser: "xml"}}},
+			expectedErr: true,
+		},
+		"invalid timeout": {
+			spec:        &CommandSpec{Commands: []Command{{Name: "test", Command: "echo", Options: &CommandOpts{Timeout: "soon"}}}},
+			expectedErr: true,
+		},
+		"invalid max output": {
+			spec:        &CommandSpec{Options: &CommandOpts{MaxOutputBytes: &tooSmall}, Commands: []Command{{Name: "test", Command: "echo"}}},
+			expectedErr: true,
+		},
+		"valid": {
+			spec: &CommandSpec{
+				Options:  &CommandOpts{Timeout: "5s", InheritEnv: &inherit},
+				Commands: []Command{{Name: "test", Command: "echo", Args: []string{"hi"}, Parser: ParserLines}},
+			},
+			expectedErr: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := CreateCommandDomain(tt.spec)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("CreateCommandDomain() error = %v, wantErr %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		got, err := mergeOptions(nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, opts{timeout: defaultTimeout, maxOutput: defaultMaxOutputBytes}, got)
+	})
+
+	t.Run("command options override spec options", func(t *testing.T) {
+		inherit, noInherit := true, false
+		got, err := mergeOptions(
+			&CommandOpts{Timeout: "5s", InheritEnv: &inherit},
+			&CommandOpts{Timeout: "1s", InheritEnv: &noInherit},
+		)
+		require.NoError(t, err)
+		require.Equal(t, opts{timeout: time.Second, maxOutput: defaultMaxOutputBytes}, got)
+	})
+}
+
+func TestBuildEnv(t *testing.T) {
+	t.Setenv("LULA_TEST_SECRET", "value")
+
+	env := buildEnv(false, map[string]string{"B": "2", "A": "1"})
+	require.NotContains(t, env, "LULA_TEST_SECRET=value")
+	require.Equal(t, []string{"A=1", "B=2"}, env[len(env)-2:])
+
+	env = buildEnv(true, nil)
+	require.Contains(t, env, "LULA_TEST_SECRET=value")
+}
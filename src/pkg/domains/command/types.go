@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// CommandDomain is a domain that is defined by a list of commands to run on the local host.
+type CommandDomain struct {
+	// the parsed and validated commands
+	commands []command
+}
+
+// command is a validated and parsed representation of the Command
+type command struct {
+	name       string
+	path       string
+	args       []string
+	env        []string
+	workingDir string
+	timeout    time.Duration
+	maxOutput  int
+	parser     Parser
+	separator  string
+}
+
+func CreateCommandDomain(spec *CommandSpec) (types.Domain, error) {
+	return validateAndMutateSpec(spec)
+}
+
+func (c CommandDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return c.runCommands(ctx)
+}
+
+// IsExecutable returns true; every command must pass the execution confirmation gate
+func (c CommandDomain) IsExecutable() bool {
+	return true
+}
+
+// User input fields for the Command Domain
+// CommandSpec contains the user-defined list of commands
+type CommandSpec struct {
+	Commands []Command `json:"commands" yaml:"commands"`
+	// Options will be applied to all commands, except where a command sets its own value
+	Options *CommandOpts `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// Command is a user-defined single command. The command is executed directly, without a shell.
+type Command struct {
+	Name    string   `json:"name" yaml:"name"`
+	Command string   `json:"command" yaml:"command"`
+	Args    []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Env are additional environment variables for the command
+	Env        map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	WorkingDir string            `json:"working-dir,omitempty" yaml:"working-dir,omitempty"`
+	// Parser for stdout: string (default), json, yaml, lines, or key-value
+	Parser Parser `json:"parser,omitempty" yaml:"parser,omitempty"`
+	// Separator between keys and values for the key-value parser, defaults to "="
+	Separator string `json:"separator,omitempty" yaml:"separator,omitempty"`
+	// CommandOpts specific to this command override the CommandSpec-level Options.
+	Options *CommandOpts `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// User-defined options which can be set at the top level (for all commands) or
+// command level (to override the top-level opts).
+type CommandOpts struct {
+	// Timeout after which the command is killed, e.g. 10s
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// InheritEnv passes the full Lula process environment to the command, otherwise only PATH is passed
+	InheritEnv *bool `json:"inherit-env,omitempty" yaml:"inherit-env,omitempty"`
+	// MaxOutputBytes truncates stdout and stderr beyond this size
+	MaxOutputBytes *int `json:"max-output-bytes,omitempty" yaml:"max-output-bytes,omitempty"`
+}
+
+type Parser string
+
+const (
+	ParserString   Parser = "string"
+	ParserJSON     Parser = "json"
+	ParserYAML     Parser = "yaml"
+	ParserLines    Parser = "lines"
+	ParserKeyValue Parser = "key-value"
+	DefaultParser  Parser = ParserString
+)
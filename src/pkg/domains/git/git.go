@@ -0,0 +1,370 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// signaturePrefixes identify the type of a commit or tag signature from its armor header
+var signaturePrefixes = map[string]string{
+	"-----BEGIN PGP SIGNATURE-----":  "pgp",
+	"-----BEGIN PGP MESSAGE-----":    "pgp",
+	"-----BEGIN SSH SIGNATURE-----":  "ssh",
+	"-----BEGIN SIGNED MESSAGE-----": "x509",
+	"-----BEGIN CERTIFICATE-----":    "x509",
+}
+
+func (d GitDomain) readRepositories(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	resources := make(types.DomainResources, len(d.Spec.Repositories))
+	var errs error
+	for _, repo := range d.Spec.Repositories {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := readRepository(ctx, repo, workDir)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("repository %s: %w", repo.Name, err))
+		}
+		if result == nil {
+			// Assign empty data value for reporting purposes
+			result = map[string]interface{}{}
+		}
+		resources[repo.Name] = result
+	}
+	return resources, errs
+}
+
+// readRepository returns the resolved head, commit log, tags and selected files of the repository.
+// Files that cannot be read are reported in the error alongside the rest of the result.
+func readRepository(ctx context.Context, repo Repository, workDir string) (map[string]interface{}, error) {
+	r, cleanup, err := openRepository(ctx, repo.Source, workDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var keyring string
+	if repo.TrustedKeys != "" {
+		b, err := network.Fetch(repo.TrustedKeys, network.WithBaseDir(workDir))
+		if err != nil {
+			return nil, fmt.Errorf("error reading trusted-keys: %w", err)
+		}
+		keyring = string(b)
+	}
+
+	refName, hash, err := resolveRef(r, repo.Ref)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commit %s: %w", hash, err)
+	}
+
+	depth := repo.LogDepth
+	if depth == 0 {
+		depth = defaultLogDepth
+	}
+	commits, err := readLog(ctx, r, hash, depth, keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := readTags(r, keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]interface{})
+	if len(repo.Files) > 0 {
+		maxBytes := repo.MaxFileBytes
+		if maxBytes == 0 {
+			maxBytes = defaultMaxFileBytes
+		}
+		files, err = readFiles(commit, repo.Files, int64(maxBytes))
+	}
+
+	return map[string]interface{}{
+		"head": map[string]interface{}{
+			"ref":    refName,
+			"commit": hash.String(),
+		},
+		"commits": commits,
+		"tags":    tags,
+		"files":   files,
+	}, err
+}
+
+// openRepository opens a local repository in place, or clones the source into a temporary
+// directory that is removed by the returned cleanup function
+func openRepository(ctx context.Context, source, workDir string) (*gogit.Repository, func(), error) {
+	noop := func() {}
+
+	local := source
+	if !filepath.IsAbs(local) {
+		local = filepath.Join(workDir, local)
+	}
+	if info, err := os.Stat(local); err == nil && info.IsDir() {
+		r, err := gogit.PlainOpen(local)
+		if err != nil {
+			return nil, noop, fmt.Errorf("error opening repository %s: %w", local, err)
+		}
+		return r, noop, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "lula-git-*")
+	if err != nil {
+		return nil, noop, fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	dst, err := network.DownloadDir(ctx, filepath.Join(tmpDir, "repo"), source, workDir)
+	if err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("error cloning %s: %w", source, err)
+	}
+	r, err := gogit.PlainOpen(dst)
+	if err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("error opening cloned repository: %w", err)
+	}
+	return r, cleanup, nil
+}
+
+// resolveRef returns the full name of the ref and the commit it points to. Refs are looked up as
+// a local branch, a branch of origin, a tag, a full reference name, and finally a commit hash.
+func resolveRef(r *gogit.Repository, ref string) (string, plumbing.Hash, error) {
+	if ref == "" || ref == "HEAD" {
+		head, err := r.Head()
+		if err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("error resolving HEAD: %w", err)
+		}
+		return head.Name().String(), head.Hash(), nil
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.NewRemoteReferenceName("origin", ref),
+		plumbing.NewTagReferenceName(ref),
+		plumbing.ReferenceName(ref),
+	}
+	for _, name := range candidates {
+		if _, err := r.Reference(name, true); err != nil {
+			continue
+		}
+		// resolving by revision peels annotated tags to the commit they point to
+		hash, err := r.ResolveRevision(plumbing.Revision(name))
+		if err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("error resolving ref %s: %w", name, err)
+		}
+		return name.String(), *hash, nil
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("ref %s not found: %w", ref, err)
+	}
+	return ref, *hash, nil
+}
+
+// readLog returns up to depth commits reachable from hash, newest first
+func readLog(ctx context.Context, r *gogit.Repository, hash plumbing.Hash, depth int, keyring string) ([]interface{}, error) {
+	iter, err := r.Log(&gogit.LogOptions{From: hash})
+	if err != nil {
+		return nil, fmt.Errorf("error reading log: %w", err)
+	}
+	defer iter.Close()
+
+	commits := make([]interface{}, 0, depth)
+	for len(commits) < depth {
+		select {
+		case <-ctx.Done():
+			return commits, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		c, err := iter.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return commits, fmt.Errorf("error reading log: %w", err)
+		}
+		commits = append(commits, map[string]interface{}{
+			"hash":      c.Hash.String(),
+			"author":    identity(c.Author),
+			"committer": identity(c.Committer),
+			"date":      c.Author.When.UTC().Format(time.RFC3339),
+			"message":   c.Message,
+			"parents":   hashes(c.ParentHashes),
+			"signature": signature(c.PGPSignature, keyring, c.Verify),
+		})
+	}
+	return commits, nil
+}
+
+// readTags returns every tag in the repository with the commit it points to
+func readTags(r *gogit.Repository, keyring string) ([]interface{}, error) {
+	iter, err := r.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("error reading tags: %w", err)
+	}
+	defer iter.Close()
+
+	tags := make([]interface{}, 0)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tag := map[string]interface{}{
+			"name":      ref.Name().Short(),
+			"commit":    ref.Hash().String(),
+			"annotated": false,
+		}
+
+		obj, err := r.TagObject(ref.Hash())
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// lightweight tags point directly to a commit
+		case err != nil:
+			return fmt.Errorf("error reading tag %s: %w", ref.Name().Short(), err)
+		default:
+			tag["annotated"] = true
+			tag["tagger"] = identity(obj.Tagger)
+			tag["date"] = obj.Tagger.When.UTC().Format(time.RFC3339)
+			tag["message"] = obj.Message
+			tag["signature"] = signature(obj.PGPSignature, keyring, obj.Verify)
+			if c, err := obj.Commit(); err == nil {
+				tag["commit"] = c.Hash.String()
+			} else {
+				// tags of trees or blobs report the target object instead
+				tag["commit"] = obj.Target.String()
+			}
+		}
+		tags = append(tags, tag)
+		return nil
+	})
+	return tags, err
+}
+
+// readFiles returns the contents of the regular files in the commit tree matching any of the
+// patterns, keyed by path relative to the repository root
+func readFiles(commit *object.Commit, patterns []string, maxBytes int64) (map[string]interface{}, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("error reading tree of commit %s: %w", commit.Hash, err)
+	}
+
+	files := make(map[string]interface{})
+	var errs error
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode != filemode.Regular && f.Mode != filemode.Executable {
+			return nil
+		}
+		if !matchesAny(f.Name, patterns) {
+			return nil
+		}
+		if f.Size > maxBytes {
+			errs = errors.Join(errs, fmt.Errorf("file %s exceeds %d bytes", f.Name, maxBytes))
+			return nil
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error reading %s: %w", f.Name, err))
+			return nil
+		}
+		files[f.Name] = contents
+		return nil
+	})
+	if err != nil {
+		errs = errors.Join(errs, fmt.Errorf("error reading tree of commit %s: %w", commit.Hash, err))
+	}
+	return files, errs
+}
+
+// signature describes the signature of a commit or tag. PGP signatures are verified when a
+// keyring of trusted keys is provided.
+func signature(sig, keyring string, verify func(string) (*openpgp.Entity, error)) map[string]interface{} {
+	result := map[string]interface{}{
+		"signed":   sig != "",
+		"type":     "",
+		"verified": false,
+	}
+	if sig == "" {
+		return result
+	}
+
+	sigType := "unknown"
+	for prefix, t := range signaturePrefixes {
+		if strings.HasPrefix(sig, prefix) {
+			sigType = t
+			break
+		}
+	}
+	result["type"] = sigType
+
+	if keyring == "" {
+		return result
+	}
+	if sigType != "pgp" {
+		result["error"] = fmt.Sprintf("verification of %s signatures is not supported", sigType)
+		return result
+	}
+	entity, err := verify(keyring)
+	if err != nil {
+		result["error"] = err.Error()
+		return result
+	}
+	result["verified"] = true
+	if id := entity.PrimaryIdentity(); id != nil {
+		result["signer"] = id.Name
+	}
+	return result
+}
+
+func identity(sig object.Signature) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  sig.Name,
+		"email": sig.Email,
+	}
+}
+
+func hashes(hs []plumbing.Hash) []interface{} {
+	result := make([]interface{}, 0, len(hs))
+	for _, h := range hs {
+		result = append(result, h.String())
+	}
+	return result
+}
+
+func matchesAny(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated when the domain is created
+		if matched, _ := path.Match(pattern, filePath); matched {
+			return true
+		}
+	}
+	return false
+}
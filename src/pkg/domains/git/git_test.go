@@ -0,0 +1,229 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*GitDomain)(nil)
+
+// testRepository creates a repository with an unsigned and a signed commit, a lightweight
+// and an annotated tag, and a feature branch. It returns the armored public key of the signer.
+func testRepository(t *testing.T, dir string) string {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+	var pub bytes.Buffer
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	r, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+
+	author := &object.Signature{Name: "Dev", Email: "dev@example.com", When: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	commit := func(files map[string]string, message string, key *openpgp.Entity) plumbing.Hash {
+		for name, contents := range files {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+			_, err := wt.Add(name)
+			require.NoError(t, err)
+		}
+		hash, err := wt.Commit(message, &gogit.CommitOptions{Author: author, SignKey: key})
+		require.NoError(t, err)
+		author.When = author.When.Add(time.Hour)
+		return hash
+	}
+
+	first := commit(map[string]string{
+		"CODEOWNERS":               "* @org/maintainers\n",
+		".github/workflows/ci.yml": "name: ci\n",
+		"large.txt":                strings.Repeat("x", 2048),
+	}, "initial commit\n", nil)
+	_, err = r.CreateTag("v0.1.0", first, nil)
+	require.NoError(t, err)
+
+	second := commit(map[string]string{"README.md": "# app\n"}, "add readme\n", entity)
+	_, err = r.CreateTag("v0.2.0", second, &gogit.CreateTagOptions{Tagger: author, Message: "release v0.2.0\n"})
+	require.NoError(t, err)
+
+	require.NoError(t, wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}))
+	commit(map[string]string{"CODEOWNERS": "* @org/feature\n"}, "change owners\n", nil)
+	require.NoError(t, wt.Checkout(&gogit.CheckoutOptions{Branch: plumbing.Master}))
+
+	return pub.String()
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pub := testRepository(t, filepath.Join(dir, "repo"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys.asc"), []byte(pub), 0o600))
+
+	domain, err := CreateGitDomain(&GitSpec{
+		Repositories: []Repository{
+			{Name: "head", Source: "repo", Files: []string{"CODEOWNERS", ".github/workflows/*.yml", "large.txt"}, MaxFileBytes: 1024, TrustedKeys: "keys.asc"},
+			{Name: "feature", Source: "repo", Ref: "feature", LogDepth: 1, Files: []string{"CODEOWNERS"}},
+			{Name: "tag", Source: "repo", Ref: "v0.2.0"},
+			{Name: "missing", Source: "does-not-exist"},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+	resources, err := domain.GetResources(ctx)
+	require.ErrorContains(t, err, "repository head: file large.txt exceeds 1024 bytes")
+	require.ErrorContains(t, err, "repository missing:")
+	require.Equal(t, map[string]interface{}{}, resources["missing"])
+
+	head := resources["head"].(map[string]interface{})
+	require.Equal(t, "refs/heads/master", head["head"].(map[string]interface{})["ref"])
+	commits := head["commits"].([]interface{})
+	require.Len(t, commits, 2)
+
+	signed := commits[0].(map[string]interface{})
+	require.Equal(t, "add readme\n", signed["message"])
+	require.Equal(t, "2024-01-02T04:04:05Z", signed["date"])
+	require.Equal(t, map[string]interface{}{"name": "Dev", "email": "dev@example.com"}, signed["author"])
+	require.Equal(t, map[string]interface{}{
+		"signed":   true,
+		"type":     "pgp",
+		"verified": true,
+		"signer":   "Release Bot <release@example.com>",
+	}, signed["signature"])
+	require.Equal(t, false, commits[1].(map[string]interface{})["signature"].(map[string]interface{})["signed"])
+
+	tags := head["tags"].([]interface{})
+	require.Len(t, tags, 2)
+	lightweight := tags[0].(map[string]interface{})
+	require.Equal(t, "v0.1.0", lightweight["name"])
+	require.Equal(t, false, lightweight["annotated"])
+	require.Equal(t, commits[1].(map[string]interface{})["hash"], lightweight["commit"])
+	annotated := tags[1].(map[string]interface{})
+	require.Equal(t, true, annotated["annotated"])
+	require.Equal(t, "release v0.2.0\n", annotated["message"])
+	require.Equal(t, signed["hash"], annotated["commit"])
+
+	require.Equal(t, map[string]interface{}{
+		"CODEOWNERS":               "* @org/maintainers\n",
+		".github/workflows/ci.yml": "name: ci\n",
+	}, head["files"])
+
+	feature := resources["feature"].(map[string]interface{})
+	require.Equal(t, "refs/heads/feature", feature["head"].(map[string]interface{})["ref"])
+	require.Len(t, feature["commits"], 1)
+	require.Equal(t, map[string]interface{}{"CODEOWNERS": "* @org/feature\n"}, feature["files"])
+
+	tag := resources["tag"].(map[string]interface{})
+	require.Equal(t, "refs/tags/v0.2.0", tag["head"].(map[string]interface{})["ref"])
+	require.Equal(t, signed["hash"], tag["head"].(map[string]interface{})["commit"])
+}
+
+func TestGetResourcesUntrustedKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testRepository(t, filepath.Join(dir, "repo"))
+	other := testRepository(t, filepath.Join(dir, "other"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys.asc"), []byte(other), 0o600))
+
+	domain, err := CreateGitDomain(&GitSpec{Repositories: []Repository{{Name: "repo", Source: "repo", TrustedKeys: "keys.asc"}}})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+	resources, err := domain.GetResources(ctx)
+	require.NoError(t, err)
+
+	commits := resources["repo"].(map[string]interface{})["commits"].([]interface{})
+	sig := commits[0].(map[string]interface{})["signature"].(map[string]interface{})
+	require.Equal(t, true, sig["signed"])
+	require.Equal(t, false, sig["verified"])
+	require.NotEmpty(t, sig["error"])
+}
+
+func TestGetResourcesClone(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required to clone with go-getter")
+	}
+
+	dir := t.TempDir()
+	testRepository(t, dir)
+
+	domain, err := CreateGitDomain(&GitSpec{Repositories: []Repository{{Name: "repo", Source: "git::file://" + dir + "?ref=feature", Files: []string{"CODEOWNERS"}}}})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(context.Background())
+	require.NoError(t, err)
+	repo := resources["repo"].(map[string]interface{})
+	require.Len(t, repo["commits"], 3)
+	require.Equal(t, map[string]interface{}{"CODEOWNERS": "* @org/feature\n"}, repo["files"])
+}
+
+func TestResolveRef(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	testRepository(t, dir)
+	r, err := gogit.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := r.Head()
+	require.NoError(t, err)
+
+	name, hash, err := resolveRef(r, head.Hash().String()[:10])
+	require.NoError(t, err)
+	require.Equal(t, head.Hash(), hash)
+	require.Equal(t, head.Hash().String()[:10], name)
+
+	_, _, err = resolveRef(r, "does-not-exist")
+	require.Error(t, err)
+}
+
+func TestCreateGitDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *GitSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no repositories", spec: &GitSpec{}, wantErr: true},
+		{name: "valid", spec: &GitSpec{Repositories: []Repository{{Name: "a", Source: "git::https://github.com/org/repo.git", Files: []string{"CODEOWNERS"}}}}},
+		{name: "missing name", spec: &GitSpec{Repositories: []Repository{{Source: "repo"}}}, wantErr: true},
+		{name: "duplicate name", spec: &GitSpec{Repositories: []Repository{{Name: "a", Source: "a"}, {Name: "a", Source: "b"}}}, wantErr: true},
+		{name: "missing source", spec: &GitSpec{Repositories: []Repository{{Name: "a"}}}, wantErr: true},
+		{name: "negative log-depth", spec: &GitSpec{Repositories: []Repository{{Name: "a", Source: "a", LogDepth: -1}}}, wantErr: true},
+		{name: "invalid pattern", spec: &GitSpec{Repositories: []Repository{{Name: "a", Source: "a", Files: []string{"["}}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateGitDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateGitDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// validateSpec validates the entire spec and may return multiple errors
+func validateSpec(spec *GitSpec) (errs error) {
+	if spec == nil {
+		return errors.New("spec is required")
+	}
+	if len(spec.Repositories) == 0 {
+		return errors.New("some repositories must be specified")
+	}
+
+	names := make(map[string]bool, len(spec.Repositories))
+	for _, repo := range spec.Repositories {
+		if repo.Name == "" {
+			errs = errors.Join(errs, errors.New("repository name cannot be empty"))
+		} else if names[repo.Name] {
+			errs = errors.Join(errs, fmt.Errorf("repository name %s must be unique", repo.Name))
+		}
+		names[repo.Name] = true
+
+		if err := repo.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("repository %s: %w", repo.Name, err))
+		}
+	}
+	return errs
+}
+
+func (r Repository) validate() (errs error) {
+	if r.Source == "" {
+		errs = errors.Join(errs, errors.New("source cannot be empty"))
+	}
+	if r.LogDepth < 0 {
+		errs = errors.Join(errs, errors.New("log-depth cannot be negative"))
+	}
+	for _, pattern := range r.Files {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid file pattern %s: %w", pattern, err))
+		}
+	}
+	if r.MaxFileBytes < 0 {
+		errs = errors.Join(errs, errors.New("max-file-bytes cannot be negative"))
+	}
+	return errs
+}
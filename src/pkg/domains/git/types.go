@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+const (
+	// defaultLogDepth is the number of commits returned from the ref
+	defaultLogDepth = 20
+	// defaultMaxFileBytes limits the size of each file returned from the repository
+	defaultMaxFileBytes = 1024 * 1024
+)
+
+// GitDomain reads the commit log, tags and files of git repositories, either local or cloned
+// from any source supported by go-getter.
+type GitDomain struct {
+	Spec *GitSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// GitSpec is the user-defined specification of repositories to read
+type GitSpec struct {
+	Repositories []Repository `json:"repositories" yaml:"repositories"`
+}
+
+// Repository is a single repository to read
+type Repository struct {
+	// Name is the key of the repository in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Source is a local repository path, or a go-getter source to clone, e.g. git::https://github.com/org/repo.git
+	Source string `json:"source" yaml:"source"`
+	// Ref is the branch, tag or commit the log and files are read from, defaults to HEAD
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// LogDepth is the number of commits returned, defaults to 20
+	LogDepth int `json:"log-depth,omitempty" yaml:"log-depth,omitempty"`
+	// Files are glob patterns of paths relative to the repository root to read at the ref, e.g. CODEOWNERS or .github/workflows/*.yaml
+	Files []string `json:"files,omitempty" yaml:"files,omitempty"`
+	// MaxFileBytes is the largest file that is returned, defaults to 1MiB
+	MaxFileBytes int `json:"max-file-bytes,omitempty" yaml:"max-file-bytes,omitempty"`
+	// TrustedKeys is a file of armored PGP public keys that commit and tag signatures are verified against
+	TrustedKeys string `json:"trusted-keys,omitempty" yaml:"trusted-keys,omitempty"`
+}
+
+func CreateGitDomain(spec *GitSpec) (types.Domain, error) {
+	if err := validateSpec(spec); err != nil {
+		return nil, err
+	}
+	return GitDomain{Spec: spec}, nil
+}
+
+// GetResources returns the log, tags and files of each repository keyed by repository name
+func (d GitDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.readRepositories(ctx)
+}
+
+// IsExecutable returns false; repositories are only cloned and read.
+func (d GitDomain) IsExecutable() bool { return false }
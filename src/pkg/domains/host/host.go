@@ -0,0 +1,326 @@
+package host
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+const defaultRoot = "/"
+
+// tcpListenState and udpUnconnectedState are the /proc/net socket states reported as listening
+const (
+	tcpListenState      = "0A"
+	udpUnconnectedState = "07"
+)
+
+func (h HostDomain) collectFacts(ctx context.Context) (types.DomainResources, error) {
+	root := h.Spec.Root
+	if root == "" {
+		root = defaultRoot
+	} else if !filepath.IsAbs(root) {
+		// relative roots are resolved from the directory of the validation
+		if workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string); ok {
+			root = filepath.Join(workDir, root)
+		}
+	}
+
+	facts := h.Spec.Facts
+	if len(facts) == 0 {
+		facts = AllFacts
+	}
+
+	resources := make(types.DomainResources, len(facts))
+	var errs error
+	for _, fact := range facts {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		var value interface{}
+		var err error
+		switch fact {
+		case FactSysctl:
+			value, err = readSysctl(root, h.Spec.Sysctl)
+		case FactUsers:
+			value, err = readUsers(root)
+		case FactGroups:
+			value, err = readGroups(root)
+		case FactListeningPorts:
+			value, err = readListeningPorts(root)
+		case FactMounts:
+			value, err = readMounts(root)
+		case FactKernelModules:
+			value, err = readKernelModules(root)
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error collecting %s: %w", fact, err))
+		}
+		resources[string(fact)] = value
+	}
+	return resources, errs
+}
+
+// readSysctl returns the kernel parameters under /proc/sys keyed by their dotted sysctl name.
+// Parameters which cannot be read (e.g. write-only or permission restricted) are skipped.
+func readSysctl(root string, prefixes []string) (map[string]interface{}, error) {
+	base := filepath.Join(root, "proc", "sys")
+	params := make(map[string]interface{})
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == base {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return nil
+		}
+		key := strings.ReplaceAll(filepath.ToSlash(rel), "/", ".")
+		if !hasPrefix(key, prefixes) {
+			return nil
+		}
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil
+		}
+		params[key] = strings.Join(strings.Fields(string(b)), " ")
+		return nil
+	})
+	return params, err
+}
+
+func hasPrefix(key string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if key == prefix || strings.HasPrefix(key, strings.TrimSuffix(prefix, ".")+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// readUsers parses /etc/passwd
+func readUsers(root string) ([]interface{}, error) {
+	users := make([]interface{}, 0)
+	err := readColonFile(filepath.Join(root, "etc", "passwd"), 7, func(fields []string) {
+		users = append(users, map[string]interface{}{
+			"name":  fields[0],
+			"uid":   atoi(fields[2]),
+			"gid":   atoi(fields[3]),
+			"gecos": fields[4],
+			"home":  fields[5],
+			"shell": fields[6],
+		})
+	})
+	return users, err
+}
+
+// readGroups parses /etc/group
+func readGroups(root string) ([]interface{}, error) {
+	groups := make([]interface{}, 0)
+	err := readColonFile(filepath.Join(root, "etc", "group"), 4, func(fields []string) {
+		members := make([]interface{}, 0)
+		for _, member := range strings.Split(fields[3], ",") {
+			if member != "" {
+				members = append(members, member)
+			}
+		}
+		groups = append(groups, map[string]interface{}{
+			"name":    fields[0],
+			"gid":     atoi(fields[2]),
+			"members": members,
+		})
+	})
+	return groups, err
+}
+
+// readColonFile calls fn with the fields of each well-formed line of a colon separated file
+func readColonFile(path string, numFields int, fn func([]string)) error {
+	return readLines(path, func(line string) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < numFields {
+			return
+		}
+		fn(fields)
+	})
+}
+
+// readListeningPorts parses the TCP sockets in the LISTEN state and unconnected UDP sockets
+// from /proc/net
+func readListeningPorts(root string) ([]interface{}, error) {
+	ports := make([]interface{}, 0)
+	var errs error
+	found := false
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		path := filepath.Join(root, "proc", "net", protocol)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		found = true
+
+		listenState := tcpListenState
+		if strings.HasPrefix(protocol, "udp") {
+			listenState = udpUnconnectedState
+		}
+
+		err := readLines(path, func(line string) {
+			fields := strings.Fields(line)
+			// Skip the header and malformed lines
+			if len(fields) < 10 || fields[0] == "sl" || fields[3] != listenState {
+				return
+			}
+			address, port, err := parseSocketAddress(fields[1])
+			if err != nil {
+				return
+			}
+			ports = append(ports, map[string]interface{}{
+				"protocol": protocol,
+				"address":  address,
+				"port":     port,
+				"uid":      atoi(fields[7]),
+				"inode":    fields[9],
+			})
+		})
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	if !found {
+		errs = errors.Join(errs, fmt.Errorf("no socket tables found in %s", filepath.Join(root, "proc", "net")))
+	}
+	return ports, errs
+}
+
+// parseSocketAddress decodes a /proc/net address such as 0100007F:0016, where the IP is
+// stored as host-endian (little-endian) 32-bit words
+func parseSocketAddress(s string) (string, int, error) {
+	hexIP, hexPort, found := strings.Cut(s, ":")
+	if !found {
+		return "", 0, fmt.Errorf("invalid socket address %s", s)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address %s", s)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip.String(), int(port), nil
+}
+
+// readMounts parses /proc/mounts
+func readMounts(root string) ([]interface{}, error) {
+	mounts := make([]interface{}, 0)
+	err := readLines(filepath.Join(root, "proc", "mounts"), func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return
+		}
+		options := make([]interface{}, 0)
+		for _, option := range strings.Split(fields[3], ",") {
+			options = append(options, option)
+		}
+		mounts = append(mounts, map[string]interface{}{
+			"device":     unescapeMountField(fields[0]),
+			"mountpoint": unescapeMountField(fields[1]),
+			"fstype":     fields[2],
+			"options":    options,
+		})
+	})
+	return mounts, err
+}
+
+// unescapeMountField decodes the octal escapes (e.g. \040 for a space) used in /proc/mounts
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// readKernelModules parses /proc/modules
+func readKernelModules(root string) ([]interface{}, error) {
+	modules := make([]interface{}, 0)
+	err := readLines(filepath.Join(root, "proc", "modules"), func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			return
+		}
+		dependencies := make([]interface{}, 0)
+		for _, dependency := range strings.Split(fields[3], ",") {
+			if dependency != "" && dependency != "-" {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+		modules = append(modules, map[string]interface{}{
+			"name":         fields[0],
+			"size":         atoi(fields[1]),
+			"instances":    atoi(fields[2]),
+			"dependencies": dependencies,
+			"state":        fields[4],
+		})
+	})
+	return modules, err
+}
+
+func readLines(path string, fn func(string)) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// atoi returns the integer value of s, or -1 if it is not an integer
+func atoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return i
+}
@@ -0,0 +1,122 @@
+package host
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*HostDomain)(nil)
+
+func TestCreateHostDomain(t *testing.T) {
+	t.Parallel()
+
+	_, err := CreateHostDomain(nil)
+	require.Error(t, err)
+
+	_, err = CreateHostDomain(&HostSpec{Facts: []Fact{FactUsers, "processes"}})
+	require.Error(t, err)
+
+	d, err := CreateHostDomain(&HostSpec{})
+	require.NoError(t, err)
+	require.False(t, d.IsExecutable())
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	d := HostDomain{Spec: &HostSpec{Root: "root"}}
+	resources, err := d.GetResources(ctx)
+	require.NoError(t, err)
+
+	want := types.DomainResources{
+		"sysctl": map[string]interface{}{
+			"kernel.randomize_va_space": "2",
+			"net.ipv4.ip_forward":       "0",
+			"net.ipv4.tcp_rmem":         "4096 87380 6291456",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "root", "uid": 0, "gid": 0, "gecos": "root", "home": "/root", "shell": "/bin/bash"},
+			map[string]interface{}{"name": "daemon", "uid": 1, "gid": 1, "gecos": "daemon", "home": "/usr/sbin", "shell": "/usr/sbin/nologin"},
+			map[string]interface{}{"name": "alice", "uid": 1000, "gid": 1000, "gecos": "Alice,,,", "home": "/home/alice", "shell": "/bin/zsh"},
+		},
+		"groups": []interface{}{
+			map[string]interface{}{"name": "root", "gid": 0, "members": []interface{}{}},
+			map[string]interface{}{"name": "sudo", "gid": 27, "members": []interface{}{"alice", "bob"}},
+			map[string]interface{}{"name": "alice", "gid": 1000, "members": []interface{}{}},
+		},
+		"listening-ports": []interface{}{
+			map[string]interface{}{"protocol": "tcp", "address": "0.0.0.0", "port": 22, "uid": 0, "inode": "12345"},
+			map[string]interface{}{"protocol": "tcp", "address": "127.0.0.1", "port": 8080, "uid": 1000, "inode": "23456"},
+			map[string]interface{}{"protocol": "tcp6", "address": "::1", "port": 22, "uid": 0, "inode": "45678"},
+			map[string]interface{}{"protocol": "udp", "address": "127.0.0.53", "port": 53, "uid": 101, "inode": "56789"},
+		},
+		"mounts": []interface{}{
+			map[string]interface{}{"device": "/dev/sda1", "mountpoint": "/", "fstype": "ext4", "options": []interface{}{"rw", "relatime"}},
+			map[string]interface{}{"device": "tmpfs", "mountpoint": "/tmp", "fstype": "tmpfs", "options": []interface{}{"rw", "nosuid", "nodev", "noexec"}},
+			map[string]interface{}{"device": "/dev/sdb1", "mountpoint": "/mnt/my data", "fstype": "xfs", "options": []interface{}{"ro", "relatime"}},
+		},
+		"kernel-modules": []interface{}{
+			map[string]interface{}{"name": "overlay", "size": 151552, "instances": 2, "dependencies": []interface{}{}, "state": "Live"},
+			map[string]interface{}{"name": "nf_nat", "size": 49152, "instances": 2, "dependencies": []interface{}{"xt_MASQUERADE", "nft_chain_nat"}, "state": "Live"},
+		},
+	}
+	if diff := cmp.Diff(want, resources); diff != "" {
+		t.Fatalf("wrong result(-want +got):\n%s\n", diff)
+	}
+}
+
+func TestGetResourcesFiltered(t *testing.T) {
+	t.Parallel()
+
+	d := HostDomain{Spec: &HostSpec{Root: "testdata/root", Facts: []Fact{FactSysctl}, Sysctl: []string{"net.ipv4"}}}
+	resources, err := d.GetResources(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, types.DomainResources{
+		"sysctl": map[string]interface{}{
+			"net.ipv4.ip_forward": "0",
+			"net.ipv4.tcp_rmem":   "4096 87380 6291456",
+		},
+	}, resources)
+}
+
+func TestGetResourcesMissingRoot(t *testing.T) {
+	t.Parallel()
+
+	d := HostDomain{Spec: &HostSpec{Root: "testdata/does-not-exist", Facts: []Fact{FactUsers, FactListeningPorts}}}
+	resources, err := d.GetResources(context.Background())
+	require.Error(t, err)
+	require.Contains(t, resources, "users")
+	require.Contains(t, resources, "listening-ports")
+}
+
+func TestParseSocketAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input       string
+		wantAddress string
+		wantPort    int
+		wantErr     bool
+	}{
+		"ipv4":         {input: "0100007F:0016", wantAddress: "127.0.0.1", wantPort: 22},
+		"ipv6 any":     {input: "00000000000000000000000000000000:01BB", wantAddress: "::", wantPort: 443},
+		"missing port": {input: "0100007F", wantErr: true},
+		"invalid ip":   {input: "0100:0016", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			address, port, err := parseSocketAddress(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSocketAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			require.Equal(t, tt.wantAddress, address)
+			require.Equal(t, tt.wantPort, port)
+		})
+	}
+}
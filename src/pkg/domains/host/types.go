@@ -0,0 +1,73 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Fact is a type of host information collected by the domain
+type Fact string
+
+const (
+	FactSysctl         Fact = "sysctl"
+	FactUsers          Fact = "users"
+	FactGroups         Fact = "groups"
+	FactListeningPorts Fact = "listening-ports"
+	FactMounts         Fact = "mounts"
+	FactKernelModules  Fact = "kernel-modules"
+)
+
+// AllFacts are collected when the spec does not list any facts
+var AllFacts = []Fact{FactSysctl, FactUsers, FactGroups, FactListeningPorts, FactMounts, FactKernelModules}
+
+// HostDomain collects read-only facts about a Linux host from /proc and /etc.
+type HostDomain struct {
+	Spec *HostSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// HostSpec is the user-defined specification of facts to collect
+type HostSpec struct {
+	// Root is the path the /proc and /etc files are read relative to, defaults to /.
+	// Setting it allows inspecting a mounted image or a fixture directory.
+	Root string `json:"root,omitempty" yaml:"root,omitempty"`
+	// Facts to collect, defaults to all facts
+	Facts []Fact `json:"facts,omitempty" yaml:"facts,omitempty"`
+	// Sysctl limits the collected kernel parameters to those with one of these prefixes, e.g. net.ipv4
+	Sysctl []string `json:"sysctl,omitempty" yaml:"sysctl,omitempty"`
+}
+
+func CreateHostDomain(spec *HostSpec) (types.Domain, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	var errs error
+	for _, fact := range spec.Facts {
+		if !isFact(fact) {
+			errs = errors.Join(errs, fmt.Errorf("unsupported fact %s", fact))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return HostDomain{Spec: spec}, nil
+}
+
+// GetResources returns the collected facts keyed by fact type
+func (h HostDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return h.collectFacts(ctx)
+}
+
+// IsExecutable returns false; the host domain only reads files.
+func (h HostDomain) IsExecutable() bool { return false }
+
+func isFact(fact Fact) bool {
+	for _, f := range AllFacts {
+		if f == fact {
+			return true
+		}
+	}
+	return false
+}
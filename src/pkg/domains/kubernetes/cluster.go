@@ -3,15 +3,19 @@ package kube
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	pkgkubernetes "github.com/defenseunicorns/pkg/kubernetes"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/e2e-framework/klient"
+
+	"github.com/mike-winberry/lulalib/src/pkg/message"
 )
 
 var (
@@ -91,3 +95,103 @@ func (c *Cluster) validateAndGetGVR(group, version, resource string) (*metav1.AP
 
 	return nil, fmt.Errorf("resource %s not found in group, %s, version, %s", resource, group, version)
 }
+
+// resolveResourceRule returns the GroupVersionResource targeted by the resource rule, resolving
+// the kind through discovery when the rule does not name the resource directly
+func (c *Cluster) resolveResourceRule(rule *ResourceRule) (schema.GroupVersionResource, error) {
+	if rule.Kind == "" {
+		return schema.GroupVersionResource{
+			Group:    rule.Group,
+			Version:  rule.Version,
+			Resource: rule.Resource,
+		}, nil
+	}
+	return resolveKind(c.clientset.Discovery(), rule)
+}
+
+// resolveKind searches the server resources for the rule's kind, which may be given as a Kind,
+// singular name, plural name or short name. When no version is specified only the server's
+// preferred versions are considered. Multiple matches across groups are reported as an error.
+func resolveKind(discoveryClient discovery.DiscoveryInterface, rule *ResourceRule) (schema.GroupVersionResource, error) {
+	group, version := rule.Group, rule.Version
+	if rule.ApiVersion != "" {
+		gv, err := schema.ParseGroupVersion(rule.ApiVersion)
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("invalid resource rule api-version %s: %v", rule.ApiVersion, err)
+		}
+		group, version = gv.Group, gv.Version
+	}
+
+	var resourceLists []*metav1.APIResourceList
+	if version != "" {
+		resourceList, err := discoveryClient.ServerResourcesForGroupVersion(schema.GroupVersion{Group: group, Version: version}.String())
+		if err != nil {
+			return schema.GroupVersionResource{}, err
+		}
+		resourceLists = []*metav1.APIResourceList{resourceList}
+	} else {
+		preferred, err := discovery.ServerPreferredResources(discoveryClient)
+		if err != nil {
+			// Unavailable aggregated APIs should not block resolution of everything else
+			if !discovery.IsGroupDiscoveryFailedError(err) {
+				return schema.GroupVersionResource{}, err
+			}
+			message.Debugf("partial discovery failure while resolving kind %s: %v", rule.Kind, err)
+		}
+		resourceLists = preferred
+	}
+
+	matches := make([]schema.GroupVersionResource, 0)
+	for _, resourceList := range resourceLists {
+		if resourceList == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		// An empty group is only a filter when paired with a version (i.e. the core group)
+		if (group != "" || version != "") && gv.Group != group {
+			continue
+		}
+		for _, apiResource := range resourceList.APIResources {
+			// Skip subresources such as pods/log
+			if strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+			if kindMatches(apiResource, rule.Kind) {
+				matches = append(matches, gv.WithResource(apiResource.Name))
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid resource rule: kind %s not found", rule.Kind)
+	case 1:
+		return matches[0], nil
+	default:
+		candidates := make([]string, 0, len(matches))
+		for _, match := range matches {
+			candidates = append(candidates, fmt.Sprintf("%s.%s/%s", match.Resource, match.Group, match.Version))
+		}
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid resource rule: kind %s is ambiguous, specify group or api-version to select one of: %s", rule.Kind, strings.Join(candidates, ", "))
+	}
+}
+
+// kindMatches reports whether the name identifies the API resource by Kind, plural, singular or short name
+func kindMatches(apiResource metav1.APIResource, name string) bool {
+	if strings.EqualFold(apiResource.Kind, name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	if apiResource.Name == lower || apiResource.SingularName == lower {
+		return true
+	}
+	for _, shortName := range apiResource.ShortNames {
+		if shortName == lower {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package kube
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResolveKind(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", ShortNames: []string{"po"}},
+				{Name: "pods/log", Kind: "Pod"},
+				{Name: "events", SingularName: "event", Kind: "Event", ShortNames: []string{"ev"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}},
+			},
+		},
+		{
+			GroupVersion: "events.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "events", SingularName: "event", Kind: "Event", ShortNames: []string{"ev"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		rule    *ResourceRule
+		want    schema.GroupVersionResource
+		wantErr bool
+	}{
+		{
+			name: "kind resolves to preferred version",
+			rule: &ResourceRule{Kind: "Deployment"},
+			want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name: "short name",
+			rule: &ResourceRule{Kind: "deploy"},
+			want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name: "plural name",
+			rule: &ResourceRule{Kind: "pods"},
+			want: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		},
+		{
+			name: "api-version selects non-preferred version",
+			rule: &ResourceRule{Kind: "Deployment", ApiVersion: "apps/v1beta1"},
+			want: schema.GroupVersionResource{Group: "apps", Version: "v1beta1", Resource: "deployments"},
+		},
+		{
+			name:    "ambiguous kind across groups",
+			rule:    &ResourceRule{Kind: "Event"},
+			wantErr: true,
+		},
+		{
+			name: "group disambiguates",
+			rule: &ResourceRule{Kind: "Event", Group: "events.k8s.io"},
+			want: schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"},
+		},
+		{
+			name: "core version disambiguates",
+			rule: &ResourceRule{Kind: "ev", Version: "v1"},
+			want: schema.GroupVersionResource{Version: "v1", Resource: "events"},
+		},
+		{
+			name:    "unknown kind",
+			rule:    &ResourceRule{Kind: "Widget"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown api-version",
+			rule:    &ResourceRule{Kind: "Deployment", ApiVersion: "apps/v2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveKind(discoveryClient, tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveKind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveKind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
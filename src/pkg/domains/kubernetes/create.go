@@ -23,7 +23,7 @@ import (
 )
 
 // CreateAllResources() creates all resources and returns their status
-func CreateAllResources(ctx context.Context, cluster *Cluster, resources []CreateResource) (map[string]interface{}, []string, error) {
+func CreateAllResources(ctx context.Context, cluster *Cluster, resources []CreateResource, redaction *Redaction) (map[string]interface{}, []string, error) {
 	collections := make(map[string]interface{}, len(resources))
 	namespaces := make([]string, 0)
 	var errList []string
@@ -52,13 +52,13 @@ func CreateAllResources(ctx context.Context, cluster *Cluster, resources []Creat
 		// TODO: Allow both Manifest and File to be specified?
 		// Want to catch any errors and proceed in case resources have already been created
 		if resource.Manifest != "" {
-			collection, err = CreateFromManifest(ctx, cluster.kclient, []byte(resource.Manifest))
+			collection, err = CreateFromManifest(ctx, cluster.kclient, []byte(resource.Manifest), redaction)
 			if err != nil {
 				message.Debugf("error creating resource from manifest: %v", err)
 				errList = append(errList, err.Error())
 			}
 		} else if resource.File != "" {
-			collection, err = CreateFromFile(ctx, cluster.kclient, resource.File)
+			collection, err = CreateFromFile(ctx, cluster.kclient, resource.File, redaction)
 			if err != nil {
 				message.Debugf("error creating resource from file: %v", err)
 				errList = append(errList, err.Error())
@@ -78,7 +78,7 @@ func CreateAllResources(ctx context.Context, cluster *Cluster, resources []Creat
 }
 
 // CreateResourceFromManifest() creates the resource from the manifest string
-func CreateFromManifest(ctx context.Context, client klient.Client, resourceBytes []byte) ([]map[string]interface{}, error) {
+func CreateFromManifest(ctx context.Context, client klient.Client, resourceBytes []byte, redaction *Redaction) ([]map[string]interface{}, error) {
 	resources := make([]map[string]interface{}, 0)
 
 	objArray, err := readResourcesFromYaml(resourceBytes)
@@ -92,19 +92,19 @@ func CreateFromManifest(ctx context.Context, client klient.Client, resourceBytes
 		}
 	}
 
-	cleanResources(&resources)
+	cleanResources(&resources, redaction)
 
 	return resources, nil
 }
 
 // CreateResourceFromFile() creates the resource from a file
-func CreateFromFile(ctx context.Context, client klient.Client, resourceFile string) ([]map[string]interface{}, error) {
+func CreateFromFile(ctx context.Context, client klient.Client, resourceFile string, redaction *Redaction) ([]map[string]interface{}, error) {
 	// Get manifest data from file and pass to CreateFromManifest
 	resourceBytes, err := network.Fetch(resourceFile)
 	if err != nil {
 		return nil, err
 	}
-	return CreateFromManifest(ctx, client, resourceBytes)
+	return CreateFromManifest(ctx, client, resourceBytes, redaction)
 }
 
 // DestroyAllResources() removes all the created resources
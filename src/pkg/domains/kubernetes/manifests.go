@@ -0,0 +1,473 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// defaultHelmReleaseName is used when rendering a chart without a release name
+const defaultHelmReleaseName = "lula"
+
+// ManifestSource is a set of manifests to evaluate the resource rules against instead of a live cluster.
+// Exactly one of Path, Kustomize or Helm must be specified.
+type ManifestSource struct {
+	// Path is a YAML/JSON file, a directory of such files (walked recursively), or a remote file URL
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Kustomize is a directory containing a kustomization to build
+	Kustomize string `json:"kustomize,omitempty" yaml:"kustomize,omitempty"`
+	// Helm is a local chart to render
+	Helm *HelmChart `json:"helm,omitempty" yaml:"helm,omitempty"`
+}
+
+// HelmChart is a local Helm chart which is rendered client-side, equivalent to `helm template --include-crds --no-hooks`
+type HelmChart struct {
+	Chart       string                 `json:"chart" yaml:"chart"`
+	ReleaseName string                 `json:"release-name,omitempty" yaml:"release-name,omitempty"`
+	Namespace   string                 `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	ValuesFiles []string               `json:"values-files,omitempty" yaml:"values-files,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Validate checks that exactly one kind of source is specified
+func (m ManifestSource) Validate() error {
+	count := 0
+	if m.Path != "" {
+		count++
+	}
+	if m.Kustomize != "" {
+		count++
+	}
+	if m.Helm != nil {
+		count++
+		if m.Helm.Chart == "" {
+			return errors.New("manifest helm chart cannot be empty")
+		}
+	}
+	if count != 1 {
+		return errors.New("manifest source must specify exactly one of path, kustomize, or helm")
+	}
+	return nil
+}
+
+// LoadManifests reads, builds or renders all manifest sources and returns the resulting objects.
+// Relative paths are resolved against workDir.
+func LoadManifests(ctx context.Context, sources []ManifestSource, workDir string) ([]map[string]interface{}, error) {
+	objects := make([]map[string]interface{}, 0)
+	for _, source := range sources {
+		var manifestBytes [][]byte
+		var err error
+
+		switch {
+		case source.Path != "":
+			manifestBytes, err = readManifestPath(source.Path, workDir)
+		case source.Kustomize != "":
+			var built []byte
+			built, err = buildKustomization(resolvePath(source.Kustomize, workDir))
+			manifestBytes = [][]byte{built}
+		case source.Helm != nil:
+			var rendered []byte
+			rendered, err = renderHelmChart(source.Helm, workDir)
+			manifestBytes = [][]byte{rendered}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range manifestBytes {
+			resources, err := readResourcesFromYaml(b)
+			if err != nil {
+				return nil, fmt.Errorf("error reading manifests: %v", err)
+			}
+			for _, resource := range resources {
+				// Skip empty documents
+				if len(resource.Object) == 0 {
+					continue
+				}
+				objects = append(objects, resource.Object)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// QueryManifests evaluates the resource rules against the manifest objects, returning the
+// collections in the same shape as QueryCluster
+func QueryManifests(objects []map[string]interface{}, resources []Resource, redaction *Redaction) (map[string]interface{}, error) {
+	collections := make(map[string]interface{}, 0)
+	var errs error
+	for _, resource := range resources {
+		collection, err := GetResourcesFromManifests(objects, resource.ResourceRule, redaction)
+		// capture error but continue with other resources
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+
+		if resource.ResourceRule != nil && resource.ResourceRule.Name != "" {
+			if len(collection) > 0 {
+				collections[resource.Name] = collection[0]
+			} else {
+				// This request returned no resources
+				collections[resource.Name] = map[string]interface{}{}
+			}
+		} else {
+			if len(collection) > 0 {
+				collections[resource.Name] = collection
+			} else {
+				// This request returned no resources
+				collections[resource.Name] = []map[string]interface{}{}
+			}
+		}
+	}
+	return collections, errs
+}
+
+// GetResourcesFromManifests returns the subset of manifest objects selected by the resource rule
+func GetResourcesFromManifests(objects []map[string]interface{}, resource *ResourceRule, redaction *Redaction) ([]map[string]interface{}, error) {
+	if resource == nil {
+		return nil, fmt.Errorf("resource rule is nil")
+	}
+
+	namespaces := slices.DeleteFunc(slices.Clone(resource.Namespaces), func(ns string) bool { return ns == "" })
+	if resource.Name != "" && len(namespaces) > 1 {
+		return nil, fmt.Errorf("named resource requested cannot be returned from multiple namespaces")
+	}
+
+	selector := labels.Everything()
+	if resource.LabelSelector != "" {
+		var err error
+		selector, err = labels.Parse(resource.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("resource rule label-selector is invalid: %v", err)
+		}
+	}
+
+	customNames := customResourceNames(objects)
+	collection := make([]map[string]interface{}, 0)
+	for _, object := range objects {
+		obj := unstructured.Unstructured{Object: object}
+		if !manifestMatchesRule(obj.GroupVersionKind(), resource, customNames) {
+			continue
+		}
+		if len(namespaces) > 0 && !slices.Contains(namespaces, obj.GetNamespace()) {
+			continue
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if resource.Name != "" && obj.GetName() != resource.Name {
+			continue
+		}
+		// Copy so that cleaning and field extraction do not modify the loaded manifests
+		collection = append(collection, obj.DeepCopy().Object)
+	}
+
+	if resource.Name != "" {
+		if len(collection) == 0 {
+			return nil, fmt.Errorf("resource %s not found in manifests", resource.Name)
+		}
+		// A name may repeat across namespaces when none was specified; keep the first, as the cluster would
+		collection = collection[:1]
+	}
+
+	// Clean the resources before a field is extracted, so that a field cannot bypass redaction
+	cleanResources(&collection, redaction)
+
+	if resource.Name != "" {
+		if resource.Field != nil && resource.Field.Jsonpath != "" {
+			item, err := getFieldValue(collection[0], resource.Field)
+			if err != nil {
+				return nil, err
+			}
+			collection[0] = item
+		}
+	}
+
+	return collection, nil
+}
+
+// resourceNames are the names a kind can be referred to by, as they would be reported by discovery
+type resourceNames struct {
+	plural     string
+	singular   string
+	shortNames []string
+}
+
+// builtinResourceNames are the short names of built-in kinds, and their plurals where these
+// cannot be guessed from the Kind
+var builtinResourceNames = map[schema.GroupKind]resourceNames{
+	{Kind: "ConfigMap"}:                                               {shortNames: []string{"cm"}},
+	{Kind: "Endpoints"}:                                               {plural: "endpoints", shortNames: []string{"ep"}},
+	{Kind: "Event"}:                                                   {shortNames: []string{"ev"}},
+	{Kind: "LimitRange"}:                                              {shortNames: []string{"limits"}},
+	{Kind: "Namespace"}:                                               {shortNames: []string{"ns"}},
+	{Kind: "Node"}:                                                    {shortNames: []string{"no"}},
+	{Kind: "PersistentVolume"}:                                        {shortNames: []string{"pv"}},
+	{Kind: "PersistentVolumeClaim"}:                                   {shortNames: []string{"pvc"}},
+	{Kind: "Pod"}:                                                     {shortNames: []string{"po"}},
+	{Kind: "ReplicationController"}:                                   {shortNames: []string{"rc"}},
+	{Kind: "ResourceQuota"}:                                           {shortNames: []string{"quota"}},
+	{Kind: "Service"}:                                                 {shortNames: []string{"svc"}},
+	{Kind: "ServiceAccount"}:                                          {shortNames: []string{"sa"}},
+	{Group: "apps", Kind: "DaemonSet"}:                                {shortNames: []string{"ds"}},
+	{Group: "apps", Kind: "Deployment"}:                               {shortNames: []string{"deploy"}},
+	{Group: "apps", Kind: "ReplicaSet"}:                               {shortNames: []string{"rs"}},
+	{Group: "apps", Kind: "StatefulSet"}:                              {shortNames: []string{"sts"}},
+	{Group: "batch", Kind: "CronJob"}:                                 {shortNames: []string{"cj"}},
+	{Group: "events.k8s.io", Kind: "Event"}:                           {shortNames: []string{"ev"}},
+	{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}:           {shortNames: []string{"hpa"}},
+	{Group: "networking.k8s.io", Kind: "Ingress"}:                     {shortNames: []string{"ing"}},
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"}:               {shortNames: []string{"netpol"}},
+	{Group: "policy", Kind: "PodDisruptionBudget"}:                    {shortNames: []string{"pdb"}},
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:               {shortNames: []string{"pc"}},
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                   {shortNames: []string{"sc"}},
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}: {shortNames: []string{"csr"}},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: {shortNames: []string{"crd", "crds"}},
+}
+
+// customResourceNames returns the names declared by the CustomResourceDefinitions among the objects
+func customResourceNames(objects []map[string]interface{}) map[schema.GroupKind]resourceNames {
+	names := make(map[schema.GroupKind]resourceNames)
+	for _, object := range objects {
+		obj := unstructured.Unstructured{Object: object}
+		if obj.GroupVersionKind().GroupKind() != (schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}) {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(object, "spec", "names", "plural")
+		singular, _, _ := unstructured.NestedString(object, "spec", "names", "singular")
+		shortNames, _, _ := unstructured.NestedStringSlice(object, "spec", "names", "shortNames")
+		if kind == "" || plural == "" {
+			continue
+		}
+		names[schema.GroupKind{Group: group, Kind: kind}] = resourceNames{plural: plural, singular: singular, shortNames: shortNames}
+	}
+	return names
+}
+
+// namesForKind returns the names of a kind, from a CustomResourceDefinition in the manifests or
+// the built-in names if known, otherwise guessed from the Kind
+func namesForKind(gvk schema.GroupVersionKind, customNames map[schema.GroupKind]resourceNames) resourceNames {
+	plural, singular := meta.UnsafeGuessKindToResource(gvk)
+	names, ok := customNames[gvk.GroupKind()]
+	if !ok {
+		names = builtinResourceNames[gvk.GroupKind()]
+	}
+	if names.plural == "" {
+		names.plural = plural.Resource
+	}
+	if names.singular == "" {
+		// the singular name of a CustomResourceDefinition defaults to the lowercase Kind
+		names.singular = singular.Resource
+	}
+	return names
+}
+
+// manifestMatchesRule reports whether an object of the given GroupVersionKind is selected by the rule
+func manifestMatchesRule(gvk schema.GroupVersionKind, rule *ResourceRule, customNames map[schema.GroupKind]resourceNames) bool {
+	names := namesForKind(gvk, customNames)
+
+	if rule.Kind == "" {
+		return gvk.Group == rule.Group && gvk.Version == rule.Version && names.plural == rule.Resource
+	}
+
+	group, version := rule.Group, rule.Version
+	if rule.ApiVersion != "" {
+		gv, err := schema.ParseGroupVersion(rule.ApiVersion)
+		if err != nil {
+			return false
+		}
+		group, version = gv.Group, gv.Version
+	}
+	if (group != "" || version != "") && gvk.Group != group {
+		return false
+	}
+	if version != "" && gvk.Version != version {
+		return false
+	}
+
+	lower := strings.ToLower(rule.Kind)
+	return strings.EqualFold(gvk.Kind, rule.Kind) || names.plural == lower || names.singular == lower || slices.Contains(names.shortNames, lower)
+}
+
+// readManifestPath returns the contents of a manifest file, every YAML/JSON file under a
+// manifest directory, or a remote manifest file
+func readManifestPath(path, workDir string) ([][]byte, error) {
+	if !network.IsFileLocal(path) {
+		b, err := network.Fetch(path)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching manifest %s: %v", path, err)
+		}
+		return [][]byte{b}, nil
+	}
+
+	path = resolvePath(strings.TrimPrefix(strings.TrimPrefix(path, "file://"), "file:"), workDir)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{b}, nil
+	}
+
+	manifests := make([][]byte, 0)
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			b, err := os.ReadFile(filepath.Clean(p))
+			if err != nil {
+				return err
+			}
+			manifests = append(manifests, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking manifest directory %s: %v", path, err)
+	}
+	return manifests, nil
+}
+
+// buildKustomization runs the kustomize build for the given directory
+func buildKustomization(dir string) ([]byte, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("error building kustomization %s: %v", dir, err)
+	}
+	return resMap.AsYaml()
+}
+
+// renderHelmChart renders the chart templates without contacting a cluster, as `helm template` would
+// with --include-crds --no-hooks. Only the chart, values and template packages of Helm are used, so that
+// rendering does not pull in the release, storage and registry clients of the Helm action package.
+func renderHelmChart(helmChart *HelmChart, workDir string) ([]byte, error) {
+	chart, err := loader.Load(resolvePath(helmChart.Chart, workDir))
+	if err != nil {
+		return nil, fmt.Errorf("error loading helm chart %s: %v", helmChart.Chart, err)
+	}
+
+	// Values files are merged in order, so that later files take precedence
+	vals := map[string]interface{}{}
+	for _, f := range helmChart.ValuesFiles {
+		fileVals, err := chartutil.ReadValuesFile(resolvePath(f, workDir))
+		if err != nil {
+			return nil, fmt.Errorf("error reading helm values: %v", err)
+		}
+		vals = chartutil.MergeTables(fileVals, vals)
+	}
+	// Inline values take precedence over values files
+	if helmChart.Values != nil {
+		vals = chartutil.MergeTables(deepCopyValues(helmChart.Values), vals)
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chart, vals); err != nil {
+		return nil, fmt.Errorf("error processing helm chart dependencies: %v", err)
+	}
+
+	options := chartutil.ReleaseOptions{
+		Name:      helmChart.ReleaseName,
+		Namespace: helmChart.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	if options.Name == "" {
+		options.Name = defaultHelmReleaseName
+	}
+	if options.Namespace == "" {
+		options.Namespace = "default"
+	}
+	caps := chartutil.DefaultCapabilities
+	if chart.Metadata.KubeVersion != "" && !chartutil.IsCompatibleRange(chart.Metadata.KubeVersion, caps.KubeVersion.String()) {
+		return nil, fmt.Errorf("helm chart %s requires kubeVersion %s which is incompatible with Kubernetes %s", helmChart.Chart, chart.Metadata.KubeVersion, caps.KubeVersion.String())
+	}
+	renderVals, err := chartutil.ToRenderValues(chart, vals, options, caps)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering helm chart %s: %v", helmChart.Chart, err)
+	}
+
+	files, err := engine.Render(chart, renderVals)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering helm chart %s: %v", helmChart.Chart, err)
+	}
+	for name := range files {
+		if strings.HasSuffix(name, "NOTES.txt") {
+			delete(files, name)
+		}
+	}
+	// As in the manifest of a release, hooks are left out and partials and empty templates are dropped
+	_, manifests, err := releaseutil.SortManifests(files, nil, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering helm chart %s: %v", helmChart.Chart, err)
+	}
+
+	var rendered bytes.Buffer
+	for _, crd := range chart.CRDObjects() {
+		fmt.Fprintf(&rendered, "---\n%s\n", crd.File.Data)
+	}
+	for _, manifest := range manifests {
+		fmt.Fprintf(&rendered, "---\n%s\n", manifest.Content)
+	}
+	return rendered.Bytes(), nil
+}
+
+// resolvePath joins relative paths to the working directory
+func resolvePath(path, workDir string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(workDir, path)
+}
+
+// deepCopyValues copies the inline values, since MergeTables modifies its destination
+func deepCopyValues(vals map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(vals))
+	for k, v := range vals {
+		if nested, ok := v.(map[string]interface{}); ok {
+			copied[k] = deepCopyValues(nested)
+		} else {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+// getManifestWorkDir returns the directory manifest paths are relative to
+func getManifestWorkDir(ctx context.Context) string {
+	if workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string); ok {
+		return workDir
+	}
+	// if unset, assume lula is already working in the same directory the inputFile is in
+	return "."
+}
@@ -0,0 +1,219 @@
+package kube_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	kube "github.com/mike-winberry/lulalib/src/pkg/domains/kubernetes"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+func TestManifestsGetResources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+
+	t.Run("manifest directory", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "manifests"}},
+			Resources: []kube.Resource{
+				{Name: "deployments", ResourceRule: &kube.ResourceRule{Group: "apps", Version: "v1", Resource: "deployments"}},
+				{Name: "configmaps", ResourceRule: &kube.ResourceRule{Kind: "ConfigMap", Namespaces: []string{"validation-test"}}},
+				{Name: "all-configmaps", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "configmaps"}},
+				{Name: "config", ResourceRule: &kube.ResourceRule{
+					Name:       "app-config",
+					Version:    "v1",
+					Resource:   "configmaps",
+					Namespaces: []string{"validation-test"},
+					Field:      &kube.Field{Jsonpath: ".data.config.json"},
+				}},
+				{Name: "pods", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "pods"}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+
+		deployments, ok := resources["deployments"].([]map[string]interface{})
+		require.True(t, ok)
+		require.Len(t, deployments, 1)
+		require.Equal(t, "nginx", deployments[0]["metadata"].(map[string]interface{})["name"])
+
+		require.Len(t, resources["configmaps"], 1)
+		require.Len(t, resources["all-configmaps"], 2)
+		require.Equal(t, map[string]interface{}{"tls": true}, resources["config"])
+		require.Equal(t, []map[string]interface{}{}, resources["pods"])
+	})
+
+	t.Run("label selector", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "manifests"}},
+			Resources: []kube.Resource{
+				{Name: "web", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "configmaps", LabelSelector: "app=web"}},
+				{Name: "not-web", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "configmaps", LabelSelector: "app,app!=web"}},
+				{Name: "none", ResourceRule: &kube.ResourceRule{Group: "apps", Version: "v1", Resource: "deployments", LabelSelector: "app in (web, cache)"}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+
+		web := resources["web"].([]map[string]interface{})
+		require.Len(t, web, 1)
+		require.Equal(t, "app-config", web[0]["metadata"].(map[string]interface{})["name"])
+		notWeb := resources["not-web"].([]map[string]interface{})
+		require.Len(t, notWeb, 1)
+		require.Equal(t, "other-config", notWeb[0]["metadata"].(map[string]interface{})["name"])
+		require.Equal(t, []map[string]interface{}{}, resources["none"])
+	})
+
+	t.Run("resource names", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "custom"}},
+			Resources: []kube.Resource{
+				// names of a custom resource are read from its CustomResourceDefinition
+				{Name: "plural", ResourceRule: &kube.ResourceRule{Group: "example.com", Version: "v1", Resource: "octopodes"}},
+				{Name: "short", ResourceRule: &kube.ResourceRule{Kind: "oct"}},
+				{Name: "guessed", ResourceRule: &kube.ResourceRule{Group: "example.com", Version: "v1", Resource: "octopuses"}},
+				{Name: "endpoints", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "endpoints"}},
+				{Name: "builtin-short", ResourceRule: &kube.ResourceRule{Kind: "ep"}},
+				{Name: "crds", ResourceRule: &kube.ResourceRule{Kind: "crd"}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, resources["plural"], 1)
+		require.Len(t, resources["short"], 1)
+		require.Equal(t, []map[string]interface{}{}, resources["guessed"])
+		require.Len(t, resources["endpoints"], 1)
+		require.Len(t, resources["builtin-short"], 1)
+		require.Len(t, resources["crds"], 1)
+	})
+
+	t.Run("named resource not found", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "manifests/configmaps.yaml"}},
+			Resources: []kube.Resource{
+				{Name: "missing", ResourceRule: &kube.ResourceRule{Name: "missing", Version: "v1", Resource: "configmaps"}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.Error(t, err)
+		require.Equal(t, map[string]interface{}{}, resources["missing"])
+	})
+
+	t.Run("kustomize", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Kustomize: "kustomize"}},
+			Resources: []kube.Resource{
+				{Name: "services", ResourceRule: &kube.ResourceRule{Version: "v1", Resource: "services", Namespaces: []string{"kustomized"}}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+		require.Len(t, resources["services"], 1)
+	})
+
+	t.Run("helm", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Helm: &kube.HelmChart{
+				Chart:       "chart",
+				ReleaseName: "test",
+				Namespace:   "apps",
+				ValuesFiles: []string{"values-prod.yaml"},
+				Values:      map[string]interface{}{"image": "nginx:1.27"},
+			}}},
+			Resources: []kube.Resource{
+				{Name: "web", ResourceRule: &kube.ResourceRule{Name: "test-web", Kind: "deployment", Namespaces: []string{"apps"}}},
+				{Name: "crds", ResourceRule: &kube.ResourceRule{Kind: "crd"}},
+				// hooks are not part of the rendered manifests
+				{Name: "pods", ResourceRule: &kube.ResourceRule{Kind: "pod"}},
+			},
+		})
+		require.NoError(t, err)
+
+		resources, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+
+		web, ok := resources["web"].(map[string]interface{})
+		require.True(t, ok)
+		spec := web["spec"].(map[string]interface{})
+		require.EqualValues(t, 3, spec["replicas"])
+		containers := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+		require.Equal(t, "nginx:1.27", containers[0].(map[string]interface{})["image"])
+
+		require.Len(t, resources["crds"], 1)
+		require.Equal(t, []map[string]interface{}{}, resources["pods"])
+	})
+}
+
+func TestCreateKubernetesDomainManifests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		spec        *kube.KubernetesSpec
+		expectedErr bool
+	}{
+		{
+			name: "manifests without resources",
+			spec: &kube.KubernetesSpec{
+				Manifests: []kube.ManifestSource{{Path: "manifests"}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "empty manifests",
+			spec: &kube.KubernetesSpec{
+				Manifests: []kube.ManifestSource{},
+				Resources: []kube.Resource{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "manifests with wait",
+			spec: &kube.KubernetesSpec{
+				Manifests: []kube.ManifestSource{{Path: "manifests"}},
+				Resources: []kube.Resource{},
+				Wait:      &kube.Wait{Name: "test", Version: "v1", Resource: "pods"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "manifest source with multiple types",
+			spec: &kube.KubernetesSpec{
+				Manifests: []kube.ManifestSource{{Path: "manifests", Kustomize: "kustomize"}},
+				Resources: []kube.Resource{},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "helm manifest source without chart",
+			spec: &kube.KubernetesSpec{
+				Manifests: []kube.ManifestSource{{Helm: &kube.HelmChart{}}},
+				Resources: []kube.Resource{},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kube.CreateKubernetesDomain(tt.spec)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("CreateKubernetesDomain() error = %v, wantErr %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
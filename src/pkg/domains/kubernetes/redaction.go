@@ -0,0 +1,298 @@
+package kube
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/mike-winberry/lulalib/src/pkg/message"
+)
+
+type RedactionMode string
+
+const (
+	// RedactionModeHash replaces values with "sha256:<hex digest>", allowing equality checks against a known hash
+	RedactionModeHash RedactionMode = "hash"
+	// RedactionModeMask replaces values with "*" characters of the same length, allowing presence and length checks
+	RedactionModeMask    RedactionMode = "mask"
+	DefaultRedactionMode RedactionMode = RedactionModeHash
+)
+
+// redactionHashPrefix identifies hashed values in the collected resources
+const redactionHashPrefix = "sha256:"
+
+// defaultRedactionRules are always applied unless redaction is disabled
+var defaultRedactionRules = []RedactionRule{
+	{
+		ApiVersion: "v1",
+		Kind:       "Secret",
+		Paths:      []string{".data"},
+		base64:     true,
+	},
+	{
+		ApiVersion: "v1",
+		Kind:       "Secret",
+		Paths: []string{
+			".stringData",
+			// kubectl apply stores the full secret in plain text in this annotation
+			`.metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration`,
+		},
+	},
+}
+
+// Redaction is the policy for masking or hashing sensitive values in collected resources.
+// Secret data is redacted by default; Rules add redaction for other kinds.
+type Redaction struct {
+	// Disabled turns off all redaction, including the secret defaults
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Mode is how values are redacted: hash (default) or mask
+	Mode RedactionMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// Rules are additional paths to redact by kind
+	Rules []RedactionRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// RedactionRule selects the paths to redact in resources of a kind
+type RedactionRule struct {
+	// ApiVersion optionally restricts the rule to a group/version, e.g. apps/v1
+	ApiVersion string `json:"api-version,omitempty" yaml:"api-version,omitempty"`
+	Kind       string `json:"kind" yaml:"kind"`
+	// Paths are JSONPath expressions from the top level object, as used by kubectl -o jsonpath, e.g.
+	// .spec.template.spec.containers[*].env. Dots in keys are escaped, e.g. .metadata.annotations.example\.com/token.
+	// Every value beneath a matched path is redacted.
+	Paths []string `json:"paths" yaml:"paths"`
+	// base64 decodes the values before they are redacted, so that hashes and lengths are those of the
+	// decoded values, e.g. of Secret data
+	base64 bool
+}
+
+// Validate the Redaction policy
+func (r *Redaction) Validate() error {
+	if r == nil {
+		return nil
+	}
+	switch r.Mode {
+	case "", RedactionModeHash, RedactionModeMask:
+	default:
+		return errors.New("redaction mode must be 'hash' or 'mask'")
+	}
+	for _, rule := range r.Rules {
+		if rule.Kind == "" {
+			return errors.New("redaction rule kind cannot be empty")
+		}
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("redaction rule for %s must specify paths", rule.Kind)
+		}
+		for _, path := range rule.Paths {
+			if _, err := parseRedactionPath(path); err != nil {
+				return fmt.Errorf("redaction rule for %s: %w", rule.Kind, err)
+			}
+		}
+	}
+	return nil
+}
+
+// redact applies the policy to each resource in place; a nil policy applies the defaults
+func (r *Redaction) redact(resources []map[string]interface{}) {
+	if r != nil && r.Disabled {
+		return
+	}
+
+	mode := DefaultRedactionMode
+	rules := defaultRedactionRules
+	if r != nil {
+		if r.Mode != "" {
+			mode = r.Mode
+		}
+		rules = append(append([]RedactionRule{}, defaultRedactionRules...), r.Rules...)
+	}
+
+	for _, resource := range resources {
+		obj := unstructured.Unstructured{Object: resource}
+		for _, rule := range rules {
+			if !rule.matches(obj.GetAPIVersion(), obj.GetKind()) {
+				continue
+			}
+			for _, path := range rule.Paths {
+				segments, err := parseRedactionPath(path)
+				if err != nil {
+					message.Debugf("Error parsing redaction path %s: %v", path, err)
+					continue
+				}
+				redactPath(resource, segments, mode, rule.base64)
+			}
+		}
+	}
+}
+
+func (rule RedactionRule) matches(apiVersion, kind string) bool {
+	if kind == "" || kind != rule.Kind {
+		return false
+	}
+	return rule.ApiVersion == "" || rule.ApiVersion == apiVersion
+}
+
+// redactionSegment is a step of a redaction path, which selects a key, every key or list item, or an index
+// or slice of a list
+type redactionSegment struct {
+	key      string
+	wildcard bool
+	array    *[3]jsonpath.ParamsEntry
+}
+
+// parseRedactionPath parses a JSONPath expression into its segments. The braces of a kubectl template,
+// e.g. {.data}, are optional. Filters, recursive descent, and unions are not supported.
+func parseRedactionPath(path string) ([]redactionSegment, error) {
+	expression := strings.TrimSpace(path)
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	parser, err := jsonpath.Parse("redaction", expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction path %q: %w", path, err)
+	}
+	if len(parser.Root.Nodes) != 1 {
+		return nil, fmt.Errorf("invalid redaction path %q: must be a single expression", path)
+	}
+	list, ok := parser.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok {
+		return nil, fmt.Errorf("invalid redaction path %q: must be a single expression", path)
+	}
+
+	segments := make([]redactionSegment, 0, len(list.Nodes))
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *jsonpath.FieldNode:
+			if n.Value != "" {
+				segments = append(segments, redactionSegment{key: n.Value})
+			}
+		case *jsonpath.WildcardNode:
+			segments = append(segments, redactionSegment{wildcard: true})
+		case *jsonpath.ArrayNode:
+			if n.Params[2].Known && n.Params[2].Value <= 0 {
+				return nil, fmt.Errorf("invalid redaction path %q: step must be greater than zero", path)
+			}
+			params := n.Params
+			segments = append(segments, redactionSegment{array: &params})
+		default:
+			return nil, fmt.Errorf("invalid redaction path %q: %s is not supported", path, node)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid redaction path %q: must select a field", path)
+	}
+	return segments, nil
+}
+
+// indices returns the indices of a list of length n that are selected by the index or slice of the segment,
+// as kubectl selects them. An index out of range selects nothing.
+func (s redactionSegment) indices(n int) []int {
+	params := *s.array
+	start, end, step := 0, n, 1
+	if params[0].Known {
+		start = params[0].Value
+		if start < 0 {
+			start += n
+		}
+	}
+	if params[1].Known {
+		end = params[1].Value
+		if end < 0 || (end == 0 && params[1].Derived) {
+			end += n
+		}
+	}
+	if params[2].Known {
+		step = params[2].Value
+	}
+	if start < 0 || end > n || start >= end {
+		return nil
+	}
+
+	indices := make([]int, 0, (end-start+step-1)/step)
+	for i := start; i < end; i += step {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// redactPath walks the segments from node and redacts every value beneath the end of the path
+func redactPath(node interface{}, segments []redactionSegment, mode RedactionMode, decode bool) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	redact := func(value interface{}) interface{} {
+		if len(rest) == 0 {
+			return redactValue(value, mode, decode)
+		}
+		redactPath(value, rest, mode, decode)
+		return value
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if segment.wildcard || (segment.array == nil && segment.key == key) {
+				n[key] = redact(value)
+			}
+		}
+	case []interface{}:
+		switch {
+		case segment.wildcard:
+			for i, value := range n {
+				n[i] = redact(value)
+			}
+		case segment.array != nil:
+			for _, i := range segment.indices(len(n)) {
+				n[i] = redact(n[i])
+			}
+		}
+	}
+}
+
+// redactValue redacts every leaf value, preserving the structure of maps and lists. If decode is set,
+// string values are base64 decoded before they are redacted, unless they are not valid base64.
+func redactValue(value interface{}, mode RedactionMode, decode bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = redactValue(nested, mode, decode)
+		}
+		return v
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redactValue(nested, mode, decode)
+		}
+		return v
+	case nil:
+		return nil
+	case string:
+		if decode {
+			if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return redactBytes(decoded, mode)
+			}
+		}
+		return redactBytes([]byte(v), mode)
+	default:
+		// Non-string scalars are redacted by their JSON representation
+		b, err := json.Marshal(v)
+		if err != nil {
+			return redactBytes([]byte(fmt.Sprint(v)), mode)
+		}
+		return redactBytes(b, mode)
+	}
+}
+
+func redactBytes(value []byte, mode RedactionMode) string {
+	if mode == RedactionModeMask {
+		return strings.Repeat("*", len(value))
+	}
+	sum := sha256.Sum256(value)
+	return redactionHashPrefix + hex.EncodeToString(sum[:])
+}
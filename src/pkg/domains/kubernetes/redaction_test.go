@@ -0,0 +1,150 @@
+package kube_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	kube "github.com/mike-winberry/lulalib/src/pkg/domains/kubernetes"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+func TestRedaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	resources := []kube.Resource{
+		{Name: "secret", ResourceRule: &kube.ResourceRule{Name: "db-credentials", Version: "v1", Resource: "secrets", Namespaces: []string{"validation-test"}}},
+		{Name: "config", ResourceRule: &kube.ResourceRule{Name: "app-config", Version: "v1", Resource: "configmaps", Namespaces: []string{"validation-test"}}},
+	}
+
+	getResources := func(t *testing.T, redaction *kube.Redaction) types.DomainResources {
+		t.Helper()
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "redaction/secret.yaml"}},
+			Resources: resources,
+			Redaction: redaction,
+		})
+		require.NoError(t, err)
+		drs, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+		return drs
+	}
+
+	field := func(resource interface{}, keys ...string) interface{} {
+		current := resource
+		for _, key := range keys {
+			current = current.(map[string]interface{})[key]
+		}
+		return current
+	}
+
+	hash := func(value string) string {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	t.Run("secrets are hashed by default", func(t *testing.T) {
+		drs := getResources(t, nil)
+		// data is hashed by its decoded value
+		require.Equal(t, hash("hunter2"), field(drs["secret"], "data", "password"))
+		require.NotContains(t, field(drs["secret"], "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"), "aHVudGVyMg==")
+		require.Equal(t, "abc123", field(drs["config"], "data", "api-token"))
+	})
+
+	t.Run("mask mode with rules", func(t *testing.T) {
+		drs := getResources(t, &kube.Redaction{
+			Mode:  kube.RedactionModeMask,
+			Rules: []kube.RedactionRule{{Kind: "ConfigMap", Paths: []string{".data.api-token"}}},
+		})
+		require.Equal(t, "*******", field(drs["secret"], "data", "password"))
+		require.Equal(t, "******", field(drs["config"], "data", "api-token"))
+		require.Equal(t, "debug", field(drs["config"], "data", "log-level"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		drs := getResources(t, &kube.Redaction{Disabled: true})
+		require.Equal(t, "aHVudGVyMg==", field(drs["secret"], "data", "password"))
+	})
+
+	t.Run("fields are extracted after redaction", func(t *testing.T) {
+		fieldResources := []kube.Resource{{
+			Name: "config",
+			ResourceRule: &kube.ResourceRule{
+				Name: "db-credentials", Version: "v1", Resource: "secrets", Namespaces: []string{"validation-test"},
+				Field: &kube.Field{Jsonpath: ".data.config", Type: kube.FieldTypeYAML, Base64: true},
+			},
+		}}
+
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "redaction/secret.yaml"}},
+			Resources: fieldResources,
+		})
+		require.NoError(t, err)
+		_, err = domain.GetResources(ctx)
+		require.Error(t, err)
+
+		domain, err = kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "redaction/secret.yaml"}},
+			Resources: fieldResources,
+			Redaction: &kube.Redaction{Disabled: true},
+		})
+		require.NoError(t, err)
+		drs, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", field(drs["config"], "password"))
+	})
+
+	t.Run("jsonpath rules", func(t *testing.T) {
+		domain, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Manifests: []kube.ManifestSource{{Path: "redaction/deployment.yaml"}},
+			Resources: []kube.Resource{{Name: "app", ResourceRule: &kube.ResourceRule{
+				Name: "app", Group: "apps", Version: "v1", Resource: "deployments", Namespaces: []string{"validation-test"},
+			}}},
+			Redaction: &kube.Redaction{
+				Mode: kube.RedactionModeMask,
+				Rules: []kube.RedactionRule{{Kind: "Deployment", Paths: []string{
+					".spec.template.spec.containers[*].env[0].value",
+					".spec.template.spec.containers[-1].image",
+					`.metadata.annotations.example\.com/token`,
+				}}},
+			},
+		})
+		require.NoError(t, err)
+		drs, err := domain.GetResources(ctx)
+		require.NoError(t, err)
+
+		containers := field(drs["app"], "spec", "template", "spec", "containers").([]interface{})
+		require.Equal(t, "******", field(containers[0], "env").([]interface{})[0].(map[string]interface{})["value"])
+		require.Equal(t, "******", field(containers[1], "env").([]interface{})[0].(map[string]interface{})["value"])
+		require.Equal(t, "app:1.0", field(containers[0], "image"))
+		require.Equal(t, "***********", field(containers[1], "image"))
+		require.Equal(t, "******", field(drs["app"], "metadata", "annotations", "example.com/token"))
+		require.Equal(t, "platform", field(drs["app"], "metadata", "annotations", "example.com/owner"))
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Resources: resources,
+			Redaction: &kube.Redaction{Mode: "encrypt"},
+		})
+		require.Error(t, err)
+
+		_, err = kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+			Resources: resources,
+			Redaction: &kube.Redaction{Rules: []kube.RedactionRule{{Kind: "ConfigMap"}}},
+		})
+		require.Error(t, err)
+
+		for _, path := range []string{".data[?(@.name == 'token')]", "..token", ".data[0,1]", "{.data} {.stringData}", ".data[::0]", "{.data"} {
+			_, err = kube.CreateKubernetesDomain(&kube.KubernetesSpec{
+				Resources: resources,
+				Redaction: &kube.Redaction{Rules: []kube.RedactionRule{{Kind: "ConfigMap", Paths: []string{path}}}},
+			})
+			require.Error(t, err, path)
+		}
+	})
+}
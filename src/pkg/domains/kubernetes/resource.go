@@ -11,12 +11,11 @@ import (
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // QueryCluster() requires context and a Payload as input and returns []unstructured.Unstructured
 // This function is used to query the cluster for all resources required for processing
-func QueryCluster(ctx context.Context, cluster *Cluster, resources []Resource) (map[string]interface{}, error) {
+func QueryCluster(ctx context.Context, cluster *Cluster, resources []Resource, redaction *Redaction) (map[string]interface{}, error) {
 	if cluster == nil {
 		return nil, fmt.Errorf("cluster is nil")
 	}
@@ -25,7 +24,7 @@ func QueryCluster(ctx context.Context, cluster *Cluster, resources []Resource) (
 	var errs error
 
 	for _, resource := range resources {
-		collection, err := GetResourcesDynamically(ctx, cluster, resource.ResourceRule)
+		collection, err := GetResourcesDynamically(ctx, cluster, resource.ResourceRule, redaction)
 		// capture error but continue with other resources
 		if err != nil {
 			errs = errors.Join(errs, err)
@@ -54,15 +53,14 @@ func QueryCluster(ctx context.Context, cluster *Cluster, resources []Resource) (
 
 // GetResourcesDynamically() requires a dynamic interface and processes GVR to return []map[string]interface{}
 // This function is used to query the cluster for specific subset of resources required for processing
-func GetResourcesDynamically(ctx context.Context, cluster *Cluster, resource *ResourceRule) ([]map[string]interface{}, error) {
+func GetResourcesDynamically(ctx context.Context, cluster *Cluster, resource *ResourceRule, redaction *Redaction) ([]map[string]interface{}, error) {
 	if resource == nil {
 		return nil, fmt.Errorf("resource rule is nil")
 	}
 
-	resourceId := schema.GroupVersionResource{
-		Group:    resource.Group,
-		Version:  resource.Version,
-		Resource: resource.Resource,
+	resourceId, err := cluster.resolveResourceRule(resource)
+	if err != nil {
+		return nil, err
 	}
 	collection := make([]map[string]interface{}, 0)
 
@@ -81,21 +79,21 @@ func GetResourcesDynamically(ctx context.Context, cluster *Cluster, resource *Re
 		if err != nil {
 			return nil, err
 		}
-		item := itemObj.Object
+		// Clean the resource before the field is extracted, so that a field cannot bypass redaction
+		collection = append(collection, itemObj.Object)
+		cleanResources(&collection, redaction)
 
 		// If field is specified, get the field data; can only occur when a single named resource is specified
 		if resource.Field != nil && resource.Field.Jsonpath != "" {
-			item, err = getFieldValue(item, resource.Field)
+			collection[0], err = getFieldValue(collection[0], resource.Field)
 			if err != nil {
 				return nil, err
 			}
 		}
-
-		collection = append(collection, item)
 	} else {
 		for _, namespace := range namespaces {
 			list, err := cluster.dynamicClient.Resource(resourceId).Namespace(namespace).
-				List(ctx, metav1.ListOptions{})
+				List(ctx, metav1.ListOptions{LabelSelector: resource.LabelSelector})
 			if err != nil {
 				return nil, err
 			}
@@ -104,10 +102,9 @@ func GetResourcesDynamically(ctx context.Context, cluster *Cluster, resource *Re
 				collection = append(collection, item.Object)
 			}
 		}
+		cleanResources(&collection, redaction)
 	}
 
-	cleanResources(&collection)
-
 	return collection, nil
 }
 
@@ -177,7 +174,8 @@ func getFieldValue(item map[string]interface{}, field *Field) (map[string]interf
 }
 
 // cleanResources() clears out unnecceary fields from the resources that contribute to noise
-func cleanResources(resources *[]map[string]interface{}) {
+// and redacts sensitive values according to the redaction policy
+func cleanResources(resources *[]map[string]interface{}, redaction *Redaction) {
 	// Removes metadata.managedFields from each item in the collection
 	// Field is long and seemingly useless for our purposes, removing to reduce noise
 	for _, c := range *resources {
@@ -185,4 +183,6 @@ func cleanResources(resources *[]map[string]interface{}) {
 			delete(metadata, "managedFields")
 		}
 	}
+
+	redaction.redact(*resources)
 }
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	"github.com/mike-winberry/lulalib/src/types"
 )
 
@@ -31,15 +34,20 @@ func CreateKubernetesDomain(spec *KubernetesSpec) (types.Domain, error) {
 			if resource.ResourceRule == nil {
 				return nil, fmt.Errorf("resource rule cannot be nil")
 			}
-			if resource.ResourceRule.Resource == "" {
-				return nil, fmt.Errorf("resource rule resource cannot be empty")
-			}
-			if resource.ResourceRule.Version == "" {
-				return nil, fmt.Errorf("resource rule version cannot be empty")
+			if err := resource.ResourceRule.validateKind(); err != nil {
+				return nil, err
 			}
 			if resource.ResourceRule.Name != "" && len(resource.ResourceRule.Namespaces) > 1 {
 				return nil, fmt.Errorf("named resource requested cannot be returned from multiple namespaces")
 			}
+			if resource.ResourceRule.LabelSelector != "" {
+				if resource.ResourceRule.Name != "" {
+					return nil, fmt.Errorf("label-selector cannot be specified with resource name")
+				}
+				if _, err := labels.Parse(resource.ResourceRule.LabelSelector); err != nil {
+					return nil, fmt.Errorf("resource rule label-selector is invalid: %v", err)
+				}
+			}
 			if resource.ResourceRule.Field != nil {
 				if resource.ResourceRule.Field.Type == "" {
 					resource.ResourceRule.Field.Type = DefaultFieldType
@@ -55,6 +63,28 @@ func CreateKubernetesDomain(spec *KubernetesSpec) (types.Domain, error) {
 		}
 	}
 
+	if err := spec.Redaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	if spec.Manifests != nil && len(spec.Manifests) == 0 {
+		// an empty list would otherwise evaluate the resources against no objects instead of the cluster
+		return nil, fmt.Errorf("manifests cannot be empty")
+	}
+	if len(spec.Manifests) > 0 {
+		if spec.Resources == nil {
+			return nil, fmt.Errorf("resources must be specified when manifests are used")
+		}
+		if spec.CreateResources != nil || spec.Wait != nil {
+			return nil, fmt.Errorf("create-resources and wait cannot be used with manifests")
+		}
+		for _, manifest := range spec.Manifests {
+			if err := manifest.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if spec.Wait != nil {
 		if spec.Wait.Resource == "" {
 			return nil, fmt.Errorf("wait resource cannot be empty")
@@ -93,6 +123,19 @@ func (k KubernetesDomain) GetResources(ctx context.Context) (types.DomainResourc
 	resources := make(types.DomainResources)
 	var namespaces []string
 
+	// Evaluate the resources against the manifests rather than the cluster
+	if len(k.Spec.Manifests) > 0 {
+		objects, err := LoadManifests(ctx, k.Spec.Manifests, getManifestWorkDir(ctx))
+		if err != nil {
+			return resources, fmt.Errorf("error in manifests: %v", err)
+		}
+		resources, err = QueryManifests(objects, k.Spec.Resources, k.Spec.Redaction)
+		if err != nil {
+			return resources, fmt.Errorf("error in query: %v", err)
+		}
+		return resources, nil
+	}
+
 	cluster, err := GetCluster()
 	if err != nil {
 		return resources, err
@@ -100,7 +143,7 @@ func (k KubernetesDomain) GetResources(ctx context.Context) (types.DomainResourc
 
 	// Evaluate the create-resources parameter
 	if k.Spec.CreateResources != nil {
-		createdResources, namespaces, err = CreateAllResources(ctx, cluster, k.Spec.CreateResources)
+		createdResources, namespaces, err = CreateAllResources(ctx, cluster, k.Spec.CreateResources, k.Spec.Redaction)
 		if err != nil {
 			return resources, fmt.Errorf("error in create: %v", err)
 		}
@@ -124,7 +167,7 @@ func (k KubernetesDomain) GetResources(ctx context.Context) (types.DomainResourc
 
 	// Evaluate the resources parameter
 	if k.Spec.Resources != nil {
-		resources, err = QueryCluster(ctx, cluster, k.Spec.Resources)
+		resources, err = QueryCluster(ctx, cluster, k.Spec.Resources, k.Spec.Redaction)
 		if err != nil {
 			return resources, fmt.Errorf("error in query: %v", err)
 		}
@@ -153,6 +196,10 @@ type KubernetesSpec struct {
 	Resources       []Resource       `json:"resources" yaml:"resources"`
 	Wait            *Wait            `json:"wait,omitempty" yaml:"wait,omitempty"`
 	CreateResources []CreateResource `json:"create-resources" yaml:"create-resources"`
+	// Manifests, when specified, are queried by the resources in place of a live cluster
+	Manifests []ManifestSource `json:"manifests,omitempty" yaml:"manifests,omitempty"`
+	// Redaction is the policy for masking or hashing sensitive values; secret data is redacted by default
+	Redaction *Redaction `json:"redaction,omitempty" yaml:"redaction,omitempty"`
 }
 
 type Resource struct {
@@ -168,6 +215,44 @@ type ResourceRule struct {
 	Resource   string   `json:"resource" yaml:"resource"`
 	Namespaces []string `json:"namespaces" yaml:"namespaces"`
 	Field      *Field   `json:"field,omitempty" yaml:"field,omitempty"`
+	// Kind is an alternative to resource; accepts a Kind, singular name, plural or short name
+	// (e.g. Deployment, deploy) which is resolved through discovery
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// ApiVersion optionally pins the group/version a Kind is resolved in (e.g. apps/v1)
+	ApiVersion string `json:"api-version,omitempty" yaml:"api-version,omitempty"`
+	// LabelSelector restricts the resources to those with matching labels, using the same
+	// syntax as kubectl --selector (e.g. app=web,tier!=cache)
+	LabelSelector string `json:"label-selector,omitempty" yaml:"label-selector,omitempty"`
+}
+
+// validateKind checks that the rule identifies its resource either by group/version/resource
+// or by kind, without mixing the two
+func (r *ResourceRule) validateKind() error {
+	if r.Kind == "" {
+		if r.ApiVersion != "" {
+			return fmt.Errorf("resource rule api-version cannot be specified without kind")
+		}
+		if r.Resource == "" {
+			return fmt.Errorf("resource rule resource cannot be empty")
+		}
+		if r.Version == "" {
+			return fmt.Errorf("resource rule version cannot be empty")
+		}
+		return nil
+	}
+
+	if r.Resource != "" {
+		return fmt.Errorf("resource rule resource and kind cannot both be specified")
+	}
+	if r.ApiVersion != "" {
+		if r.Group != "" || r.Version != "" {
+			return fmt.Errorf("resource rule api-version cannot be combined with group or version")
+		}
+		if _, err := schema.ParseGroupVersion(r.ApiVersion); err != nil {
+			return fmt.Errorf("resource rule api-version is invalid: %v", err)
+		}
+	}
+	return nil
 }
 
 type FieldType string
@@ -154,6 +154,131 @@ func TestCreateKubernetesDomain(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "valid resource-rule with kind",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Kind: "deploy",
+						},
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "valid resource-rule with kind and api-version",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Kind:       "Deployment",
+							ApiVersion: "apps/v1",
+						},
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "invalid resource-rule, kind and resource",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Kind:     "Pod",
+							Version:  "v1",
+							Resource: "pods",
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid resource-rule, api-version and version",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Kind:       "Deployment",
+							ApiVersion: "apps/v1",
+							Version:    "v1",
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid resource-rule, api-version without kind",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							ApiVersion: "apps/v1",
+							Resource:   "deployments",
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "valid resource-rule with label-selector",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Version:       "v1",
+							Resource:      "pods",
+							LabelSelector: "app=web,tier notin (cache)",
+						},
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "invalid resource-rule, malformed label-selector",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Version:       "v1",
+							Resource:      "pods",
+							LabelSelector: "app in (web",
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid resource-rule, label-selector with name",
+			spec: &kube.KubernetesSpec{
+				Resources: []kube.Resource{
+					{
+						Name: "test",
+						ResourceRule: &kube.ResourceRule{
+							Name:          "web",
+							Version:       "v1",
+							Resource:      "pods",
+							LabelSelector: "app=web",
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
 		{
 			name: "empty create-resources",
 			spec: &kube.KubernetesSpec{
@@ -0,0 +1,173 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+func (d LogsDomain) readLogs(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	now := time.Now()
+	resources := make(types.DomainResources, len(d.Spec.Logs))
+	var errs error
+	for _, log := range d.Spec.Logs {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := readLog(log, workDir, now)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("log %s: %w", log.Name, err))
+			// Assign empty data value for reporting purposes
+			result = map[string]interface{}{}
+		}
+		resources[log.Name] = result
+	}
+	return resources, errs
+}
+
+// tailChunkSize is the size of the chunks a local log is read in from its end
+const tailChunkSize = 64 * 1024
+
+// readLog returns the entries parsed from the last lines of the log within the time window,
+// with the number of lines read and the number of lines that could not be parsed
+func readLog(log Log, workDir string, now time.Time) (map[string]interface{}, error) {
+	if log.Tail > 0 {
+		// a local log is read from its end, unless its checksum must be validated against the whole file
+		u, checksum, err := network.ParseChecksum(log.Path)
+		if err == nil && checksum == "" && u.Scheme == "file" {
+			path, err := network.LocalFilePath(u, workDir)
+			if err != nil {
+				return nil, err
+			}
+			contents, err := readTail(path, log.Tail, tailChunkSize)
+			if err != nil {
+				return nil, err
+			}
+			return parseLines(log, contents, now)
+		}
+	}
+
+	b, err := network.Fetch(log.Path, network.WithBaseDir(workDir))
+	if err != nil {
+		return nil, err
+	}
+	return parseLines(log, string(b), now)
+}
+
+// readTail reads a file backwards in chunks of chunkSize until it holds the last n non-empty lines, so
+// that only the tail of a large log is read into memory
+func readTail(path string, n int, chunkSize int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	var buf []byte
+	offset := info.Size()
+	lines := 0
+	// content is whether the line being read backwards, which ends at the last newline seen, is non-empty
+	content := false
+	for offset > 0 {
+		size := min(chunkSize, offset)
+		offset -= size
+		chunk := make([]byte, size, int(size)+len(buf))
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			return "", err
+		}
+		buf = append(chunk, buf...)
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			switch buf[i] {
+			case '\n':
+				if content {
+					lines++
+					if lines == n {
+						return string(buf[i+1:]), nil
+					}
+				}
+				content = false
+			case ' ', '\t', '\r', '\v', '\f':
+			default:
+				content = true
+			}
+		}
+	}
+	return string(buf), nil
+}
+
+func parseLines(log Log, contents string, now time.Time) (map[string]interface{}, error) {
+	var since time.Time
+	if log.Since != "" {
+		var err error
+		if since, err = parseSince(log.Since, now); err != nil {
+			return nil, err
+		}
+	}
+
+	lines := splitLines(contents)
+	if log.Tail > 0 && len(lines) > log.Tail {
+		lines = lines[len(lines)-log.Tail:]
+	}
+
+	parse := newParser(log, now)
+	entries := make([]interface{}, 0, len(lines))
+	unparsed := 0
+	for _, line := range lines {
+		entry, t, err := parse(line)
+		if err != nil {
+			unparsed++
+			continue
+		}
+		if !since.IsZero() {
+			if t.IsZero() {
+				// entries without a time cannot be placed in the window
+				unparsed++
+				continue
+			}
+			if t.Before(since) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return map[string]interface{}{
+		"entries":  entries,
+		"lines":    len(lines),
+		"unparsed": unparsed,
+	}, nil
+}
+
+// splitLines returns the non-empty lines of the contents, with any carriage returns removed
+func splitLines(contents string) []string {
+	lines := make([]string, 0, strings.Count(contents, "\n")+1)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
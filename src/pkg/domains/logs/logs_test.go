@@ -0,0 +1,233 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*LogsDomain)(nil)
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	domain, err := CreateLogsDomain(&LogsSpec{
+		Logs: []Log{
+			{Name: "raw", Path: "auth.log", Tail: 2},
+			{Name: "syslog", Path: "app.log", Format: FormatSyslog5424},
+			{Name: "access", Path: "access.log", Format: FormatCombined, Since: "2024-01-02T03:05:00Z"},
+			{Name: "common", Path: "access.log", Format: FormatCommon, Tail: 1},
+			{Name: "json", Path: "app.jsonl", Format: FormatJson, Since: "2024-01-02T04:00:00Z"},
+			{
+				Name:       "audit",
+				Path:       "audit.log",
+				Format:     FormatRegex,
+				Pattern:    `^type=USER_LOGIN msg=audit\((?P<time>[\d.]+):(?P<serial>\d+)\): .*acct="(?P<acct>[^"]*)".* res=(?P<res>\w+)'$`,
+				TimeField:  "time",
+				TimeFormat: "unix",
+				Since:      "2024-01-02T03:30:00Z",
+			},
+			{Name: "missing", Path: "missing.log"},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	resources, err := domain.GetResources(ctx)
+	require.ErrorContains(t, err, "log missing:")
+	require.Equal(t, map[string]interface{}{}, resources["missing"])
+
+	require.Equal(t, map[string]interface{}{
+		"entries": []interface{}{
+			map[string]interface{}{"line": "<38>Jan 12 08:00:00 web-1 sshd[1100]: Failed password for invalid user test from 10.0.0.9 port 40000 ssh2"},
+			map[string]interface{}{"line": "this line is not syslog"},
+		},
+		"lines":    2,
+		"unparsed": 0,
+	}, resources["raw"])
+
+	syslog := resources["syslog"].(map[string]interface{})
+	require.Len(t, syslog["entries"], 2)
+	require.Equal(t, map[string]interface{}{
+		"priority":  165,
+		"facility":  20,
+		"severity":  5,
+		"version":   "1",
+		"timestamp": "2024-01-02T03:04:05.123Z",
+		"hostname":  "web-1",
+		"app-name":  "app",
+		"proc-id":   "1234",
+		"msg-id":    "ID47",
+		"structured-data": map[string]interface{}{
+			"exampleSDID@32473": map[string]interface{}{"iut": "3", "eventSource": "Application"},
+			"meta":              map[string]interface{}{"sequenceId": "1"},
+		},
+		"message": "user login succeeded",
+	}, syslog["entries"].([]interface{})[0])
+	second := syslog["entries"].([]interface{})[1].(map[string]interface{})
+	require.Equal(t, "", second["proc-id"])
+	require.Equal(t, map[string]interface{}{}, second["structured-data"])
+
+	access := resources["access"].(map[string]interface{})
+	require.Equal(t, 3, access["lines"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"remote-host": "10.0.0.2",
+			"ident":       "",
+			"user":        "alice",
+			"timestamp":   "2024-01-02T03:05:00Z",
+			"request":     "POST /api/login HTTP/1.1",
+			"method":      "POST",
+			"path":        "/api/login",
+			"protocol":    "HTTP/1.1",
+			"status":      502,
+			"bytes":       157,
+			"referer":     "https://example.com/",
+			"user-agent":  "Mozilla/5.0",
+		},
+		map[string]interface{}{
+			"remote-host": "10.0.0.3",
+			"ident":       "",
+			"user":        "",
+			"timestamp":   "2024-01-02T03:06:00Z",
+			"request":     "GET /missing HTTP/1.1",
+			"method":      "GET",
+			"path":        "/missing",
+			"protocol":    "HTTP/1.1",
+			"status":      404,
+			"bytes":       0,
+			"referer":     "",
+			"user-agent":  "curl/8.5.0",
+		},
+	}, access["entries"])
+
+	common := resources["common"].(map[string]interface{})
+	require.Len(t, common["entries"], 1)
+	require.NotContains(t, common["entries"].([]interface{})[0], "user-agent")
+
+	require.Equal(t, map[string]interface{}{
+		"entries": []interface{}{
+			map[string]interface{}{"time": "2024-01-02T05:00:00Z", "level": "error", "msg": "connection refused"},
+			map[string]interface{}{"ts": 1704171845.5, "level": "warn", "msg": "slow request"},
+		},
+		"lines":    4,
+		"unparsed": 1,
+	}, resources["json"])
+
+	require.Equal(t, map[string]interface{}{
+		"entries": []interface{}{
+			map[string]interface{}{"time": "1704168245.456", "serial": "457", "acct": "test", "res": "failed"},
+		},
+		"lines":    3,
+		"unparsed": 1,
+	}, resources["audit"])
+}
+
+func TestReadTail(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	contents := "first\n\nsecond\r\n   \nthird line\n\n\nfourth\nfifth"
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+	for _, chunkSize := range []int64{1, 2, 3, 7, tailChunkSize} {
+		for n := 1; n <= 6; n++ {
+			tail, err := readTail(path, n, chunkSize)
+			require.NoError(t, err)
+
+			want := splitLines(contents)
+			if n < len(want) {
+				want = want[len(want)-n:]
+			}
+			require.Equal(t, want, splitLines(tail), "chunk size %d, tail %d", chunkSize, n)
+		}
+	}
+}
+
+func TestParseSyslog3164(t *testing.T) {
+	t.Parallel()
+
+	b, err := os.ReadFile("testdata/auth.log")
+	require.NoError(t, err)
+
+	// entries after now are from the previous year
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	result, err := parseLines(Log{Name: "auth", Format: FormatSyslog3164, Since: "240h"}, string(b), now)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, result["lines"])
+	require.Equal(t, 1, result["unparsed"])
+	entries := result["entries"].([]interface{})
+	require.Len(t, entries, 2)
+	require.Equal(t, map[string]interface{}{
+		"timestamp": "2025-01-02T03:04:05Z",
+		"hostname":  "web-1",
+		"app-name":  "sshd",
+		"proc-id":   "1021",
+		"message":   "Accepted publickey for admin from 10.0.0.5 port 52144 ssh2",
+	}, entries[0])
+	require.Equal(t, "sudo", entries[1].(map[string]interface{})["app-name"])
+	require.Equal(t, "", entries[1].(map[string]interface{})["proc-id"])
+
+	result, err = parseLines(Log{Name: "auth", Format: FormatSyslog3164}, string(b), now)
+	require.NoError(t, err)
+	previous := result["entries"].([]interface{})[2].(map[string]interface{})
+	require.Equal(t, "2024-01-12T08:00:00Z", previous["timestamp"])
+	require.Equal(t, 4, previous["facility"])
+	require.Equal(t, 6, previous["severity"])
+}
+
+func TestParseJsonEpoch(t *testing.T) {
+	t.Parallel()
+
+	entry, ts, err := parseJson(`{"ts":1704171845.5,"msg":"slow request"}`, defaultTimeFields, time.RFC3339Nano)
+	require.NoError(t, err)
+	require.Equal(t, "slow request", entry["msg"])
+	require.Equal(t, time.Date(2024, 1, 2, 5, 4, 5, 5e8, time.UTC), ts.UTC())
+
+	_, _, err = parseJson(`["not", "an", "object"]`, defaultTimeFields, time.RFC3339Nano)
+	require.Error(t, err)
+}
+
+func TestCreateLogsDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *LogsSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no logs", spec: &LogsSpec{}, wantErr: true},
+		{name: "valid", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "/var/log/auth.log", Format: FormatSyslog3164, Tail: 100, Since: "24h"}}}},
+		{name: "valid regex", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatRegex, Pattern: `^(?P<time>\S+) (?P<msg>.*)$`, TimeField: "time", Since: "1h"}}}},
+		{name: "missing name", spec: &LogsSpec{Logs: []Log{{Path: "a.log"}}}, wantErr: true},
+		{name: "duplicate name", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log"}, {Name: "a", Path: "b.log"}}}, wantErr: true},
+		{name: "missing path", spec: &LogsSpec{Logs: []Log{{Name: "a"}}}, wantErr: true},
+		{name: "unsupported format", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: "csv"}}}, wantErr: true},
+		{name: "negative tail", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Tail: -1}}}, wantErr: true},
+		{name: "invalid since", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatJson, Since: "yesterday"}}}, wantErr: true},
+		{name: "since with raw", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Since: "1h"}}}, wantErr: true},
+		{name: "regex without pattern", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatRegex}}}, wantErr: true},
+		{name: "regex without named groups", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatRegex, Pattern: `^(\S+)$`}}}, wantErr: true},
+		{name: "regex unknown time-field", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatRegex, Pattern: `^(?P<msg>.*)$`, TimeField: "time"}}}, wantErr: true},
+		{name: "regex since without time-field", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatRegex, Pattern: `^(?P<msg>.*)$`, Since: "1h"}}}, wantErr: true},
+		{name: "pattern without regex", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatJson, Pattern: `^(?P<msg>.*)$`}}}, wantErr: true},
+		{name: "time-field with syslog", spec: &LogsSpec{Logs: []Log{{Name: "a", Path: "a.log", Format: FormatSyslog5424, TimeField: "time"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateLogsDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateLogsDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
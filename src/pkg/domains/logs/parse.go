@@ -0,0 +1,296 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parser parses a single line into an entry and its time, which is zero if the entry has none
+type parser func(line string) (map[string]interface{}, time.Time, error)
+
+var (
+	// <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG, where the priority is usually omitted in files
+	// and the timestamp may be RFC 3339 when written by rsyslog with high precision timestamps
+	syslog3164Regex = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\S+) (\S+) ([^:\[\s]+)(?:\[([^\]]*)\])?: ?(.*)$`)
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+	syslog5424Regex   = regexp.MustCompile(`^<(\d{1,3})>(\d{1,2}) (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]"]|"(?:[^"\\]|\\.)*")*\])+)(?: (.*))?$`)
+	sdElementRegex    = regexp.MustCompile(`\[([^\s\]]+)((?:[^\]"]|"(?:[^"\\]|\\.)*")*)\]`)
+	sdParamRegex      = regexp.MustCompile(`([^\s=]+)="((?:[^"\\]|\\.)*)"`)
+	sdEscapeReplacer  = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\]`, `]`)
+	commonLogRegex    = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\S+)`)
+	combinedLogRegex  = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\S+) "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)"`)
+	defaultTimeFields = []string{"time", "timestamp", "ts", "@timestamp"}
+)
+
+const (
+	// timeFormatUnix is the time-format of seconds since the epoch, e.g. 1704164645.123
+	timeFormatUnix       = "unix"
+	syslog3164TimeLayout = time.Stamp
+	accessLogTimeLayout  = "02/Jan/2006:15:04:05 -0700"
+)
+
+// newParser returns the parser of the log format. now is used to infer the year of RFC 3164
+// timestamps, which do not include it, and its location is the time zone they are read in.
+func newParser(l Log, now time.Time) parser {
+	timeFormat := l.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+
+	switch l.Format {
+	case FormatJson:
+		timeFields := defaultTimeFields
+		if l.TimeField != "" {
+			timeFields = []string{l.TimeField}
+		}
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return parseJson(line, timeFields, timeFormat)
+		}
+	case FormatSyslog3164:
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return parseSyslog3164(line, now)
+		}
+	case FormatSyslog5424:
+		return parseSyslog5424
+	case FormatCommon:
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return parseAccessLog(line, commonLogRegex)
+		}
+	case FormatCombined:
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return parseAccessLog(line, combinedLogRegex)
+		}
+	case FormatRegex:
+		// the pattern is validated when the domain is created
+		re := regexp.MustCompile(l.Pattern)
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return parseRegex(line, re, l.TimeField, timeFormat)
+		}
+	default:
+		return func(line string) (map[string]interface{}, time.Time, error) {
+			return map[string]interface{}{"line": line}, time.Time{}, nil
+		}
+	}
+}
+
+func parseJson(line string, timeFields []string, timeFormat string) (map[string]interface{}, time.Time, error) {
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return nil, time.Time{}, err
+	}
+	if entry == nil {
+		return nil, time.Time{}, errors.New("line is not a JSON object")
+	}
+
+	for _, field := range timeFields {
+		switch v := entry[field].(type) {
+		case string:
+			t, err := parseTime(v, timeFormat)
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("invalid %s: %w", field, err)
+			}
+			return entry, t, nil
+		case float64:
+			// numeric times are seconds since the epoch, e.g. the ts field of zap
+			sec, frac := splitFloat(v)
+			return entry, time.Unix(sec, frac), nil
+		}
+	}
+	return entry, time.Time{}, nil
+}
+
+func parseSyslog3164(line string, now time.Time) (map[string]interface{}, time.Time, error) {
+	m := syslog3164Regex.FindStringSubmatch(line)
+	if m == nil {
+		return nil, time.Time{}, errors.New("line is not RFC 3164 syslog")
+	}
+
+	t, err := parseSyslog3164Time(m[2], now)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	entry := map[string]interface{}{
+		"timestamp": t.UTC().Format(time.RFC3339Nano),
+		"hostname":  m[3],
+		"app-name":  m[4],
+		"proc-id":   m[5],
+		"message":   m[6],
+	}
+	if m[1] != "" {
+		addPriority(entry, m[1])
+	}
+	return entry, t, nil
+}
+
+// parseSyslog3164Time parses a timestamp without a year, which is assumed to be within the last
+// twelve months, or an RFC 3339 timestamp
+func parseSyslog3164Time(ts string, now time.Time) (time.Time, error) {
+	if strings.Contains(ts, "T") {
+		return time.Parse(time.RFC3339Nano, ts)
+	}
+	t, err := time.ParseInLocation(syslog3164TimeLayout, ts, now.Location())
+	if err != nil {
+		return t, err
+	}
+	t = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+	// allow for clock skew before assuming the entry is from last year
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, nil
+}
+
+func parseSyslog5424(line string) (map[string]interface{}, time.Time, error) {
+	m := syslog5424Regex.FindStringSubmatch(line)
+	if m == nil {
+		return nil, time.Time{}, errors.New("line is not RFC 5424 syslog")
+	}
+
+	entry := map[string]interface{}{
+		"version":         m[2],
+		"hostname":        nilValue(m[4]),
+		"app-name":        nilValue(m[5]),
+		"proc-id":         nilValue(m[6]),
+		"msg-id":          nilValue(m[7]),
+		"structured-data": parseStructuredData(m[8]),
+		// messages may be prefixed with a byte order mark to indicate UTF-8
+		"message": strings.TrimPrefix(m[9], "\ufeff"),
+	}
+	addPriority(entry, m[1])
+
+	var t time.Time
+	if m[3] != "-" {
+		var err error
+		t, err = time.Parse(time.RFC3339Nano, m[3])
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		entry["timestamp"] = t.UTC().Format(time.RFC3339Nano)
+	} else {
+		entry["timestamp"] = ""
+	}
+	return entry, t, nil
+}
+
+// parseStructuredData returns the params of each structured data element keyed by element id
+func parseStructuredData(sd string) map[string]interface{} {
+	elements := make(map[string]interface{})
+	if sd == "-" {
+		return elements
+	}
+	for _, element := range sdElementRegex.FindAllStringSubmatch(sd, -1) {
+		params := make(map[string]interface{})
+		for _, param := range sdParamRegex.FindAllStringSubmatch(element[2], -1) {
+			params[param[1]] = sdEscapeReplacer.Replace(param[2])
+		}
+		elements[element[1]] = params
+	}
+	return elements
+}
+
+func parseAccessLog(line string, re *regexp.Regexp) (map[string]interface{}, time.Time, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil, time.Time{}, errors.New("line is not an access log entry")
+	}
+
+	t, err := time.Parse(accessLogTimeLayout, m[4])
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	// the status is always three digits
+	status, _ := strconv.Atoi(m[6])
+	bytes := 0
+	if m[7] != "-" {
+		if bytes, err = strconv.Atoi(m[7]); err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid bytes: %w", err)
+		}
+	}
+
+	entry := map[string]interface{}{
+		"remote-host": m[1],
+		"ident":       nilValue(m[2]),
+		"user":        nilValue(m[3]),
+		"timestamp":   t.UTC().Format(time.RFC3339Nano),
+		"request":     m[5],
+		"method":      "",
+		"path":        "",
+		"protocol":    "",
+		"status":      status,
+		"bytes":       bytes,
+	}
+	if parts := strings.Fields(m[5]); len(parts) == 3 {
+		entry["method"], entry["path"], entry["protocol"] = parts[0], parts[1], parts[2]
+	}
+	if len(m) > 8 {
+		entry["referer"] = nilValue(m[8])
+		entry["user-agent"] = nilValue(m[9])
+	}
+	return entry, t, nil
+}
+
+func parseRegex(line string, re *regexp.Regexp, timeField, timeFormat string) (map[string]interface{}, time.Time, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil, time.Time{}, errors.New("line does not match pattern")
+	}
+
+	entry := make(map[string]interface{})
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			entry[name] = m[i]
+		}
+	}
+
+	var t time.Time
+	if timeField != "" {
+		var err error
+		t, err = parseTime(m[re.SubexpIndex(timeField)], timeFormat)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid %s: %w", timeField, err)
+		}
+	}
+	return entry, t, nil
+}
+
+// parseTime parses the value with the Go reference layout, or as seconds since the epoch if the
+// layout is unix
+func parseTime(value, layout string) (time.Time, error) {
+	if layout == timeFormatUnix {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix time %q", value)
+		}
+		sec, frac := splitFloat(f)
+		return time.Unix(sec, frac), nil
+	}
+	return time.Parse(layout, value)
+}
+
+// addPriority adds the priority and the facility and severity it encodes
+func addPriority(entry map[string]interface{}, pri string) {
+	// the priority is at most three digits
+	p, _ := strconv.Atoi(pri)
+	entry["priority"] = p
+	entry["facility"] = p / 8
+	entry["severity"] = p % 8
+}
+
+// nilValue returns an empty string for the "-" used by syslog and access logs for missing values
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+func splitFloat(f float64) (int64, int64) {
+	sec := int64(f)
+	return sec, int64((f - float64(sec)) * 1e9)
+}
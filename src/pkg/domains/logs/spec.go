@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// validateSpec validates the entire spec and may return multiple errors
+func validateSpec(spec *LogsSpec) (errs error) {
+	if spec == nil {
+		return errors.New("spec is required")
+	}
+	if len(spec.Logs) == 0 {
+		return errors.New("some logs must be specified")
+	}
+
+	names := make(map[string]bool, len(spec.Logs))
+	for _, log := range spec.Logs {
+		if log.Name == "" {
+			errs = errors.Join(errs, errors.New("log name cannot be empty"))
+		} else if names[log.Name] {
+			errs = errors.Join(errs, fmt.Errorf("log name %s must be unique", log.Name))
+		}
+		names[log.Name] = true
+
+		if err := log.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("log %s: %w", log.Name, err))
+		}
+	}
+	return errs
+}
+
+func (l Log) validate() (errs error) {
+	if l.Path == "" {
+		errs = errors.Join(errs, errors.New("path cannot be empty"))
+	}
+	if l.Tail < 0 {
+		errs = errors.Join(errs, errors.New("tail cannot be negative"))
+	}
+	if l.Since != "" {
+		if _, err := parseSince(l.Since, time.Now()); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	switch l.Format {
+	case "", FormatRaw:
+		if l.Since != "" {
+			errs = errors.Join(errs, errors.New("since cannot be used with the raw format"))
+		}
+	case FormatJson:
+	case FormatSyslog3164, FormatSyslog5424, FormatCommon, FormatCombined:
+		if l.TimeField != "" || l.TimeFormat != "" {
+			errs = errors.Join(errs, fmt.Errorf("time-field and time-format cannot be used with the %s format", l.Format))
+		}
+	case FormatRegex:
+		re, err := regexp.Compile(l.Pattern)
+		switch {
+		case l.Pattern == "":
+			errs = errors.Join(errs, errors.New("pattern is required for the regex format"))
+		case err != nil:
+			errs = errors.Join(errs, fmt.Errorf("invalid pattern: %w", err))
+		case !slices.ContainsFunc(re.SubexpNames(), func(n string) bool { return n != "" }):
+			errs = errors.Join(errs, errors.New("pattern must have named capture groups"))
+		case l.TimeField != "" && re.SubexpIndex(l.TimeField) < 0:
+			errs = errors.Join(errs, fmt.Errorf("time-field %s is not a capture group of the pattern", l.TimeField))
+		case l.Since != "" && l.TimeField == "":
+			errs = errors.Join(errs, errors.New("time-field is required to use since with the regex format"))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unsupported format %s", l.Format))
+	}
+	if l.Pattern != "" && l.Format != FormatRegex {
+		errs = errors.Join(errs, errors.New("pattern can only be used with the regex format"))
+	}
+	return errs
+}
+
+// parseSince returns the start of the time window, from either a duration before now or a timestamp
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		if d < 0 {
+			return time.Time{}, errors.New("since cannot be a negative duration")
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %s, must be a duration or RFC 3339 timestamp", since)
+	}
+	return t, nil
+}
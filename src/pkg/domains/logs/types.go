@@ -0,0 +1,75 @@
+package logs
+
+import (
+	"context"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Format is the format log lines are parsed with
+type Format string
+
+const (
+	// FormatRaw returns each line as is
+	FormatRaw Format = "raw"
+	// FormatJson parses each line as a JSON object
+	FormatJson Format = "json"
+	// FormatSyslog3164 parses BSD syslog lines, as written to /var/log by most syslog daemons
+	FormatSyslog3164 Format = "syslog-rfc3164"
+	// FormatSyslog5424 parses IETF syslog lines
+	FormatSyslog5424 Format = "syslog-rfc5424"
+	// FormatCommon parses the NCSA common access log format
+	FormatCommon Format = "common"
+	// FormatCombined parses the NCSA combined access log format, used by default by nginx and apache
+	FormatCombined Format = "combined"
+	// FormatRegex parses each line with the named capture groups of a regular expression
+	FormatRegex Format = "regex"
+)
+
+// LogsDomain reads log files and parses their lines into structured entries
+type LogsDomain struct {
+	Spec *LogsSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// LogsSpec is the user-defined specification of log files to read
+type LogsSpec struct {
+	Logs []Log `json:"logs" yaml:"logs"`
+}
+
+// Log is a single log file
+type Log struct {
+	// Name is the key of the log in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Path is a local path or URL of the log file
+	Path string `json:"path" yaml:"path"`
+	// Format is the format lines are parsed with, defaults to raw
+	Format Format `json:"format,omitempty" yaml:"format,omitempty"`
+	// Pattern is a regular expression with named capture groups, required if format is regex
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// TimeField is the field holding the time of a json or regex entry. Defaults to the first of
+	// time, timestamp, ts, or @timestamp for json.
+	TimeField string `json:"time-field,omitempty" yaml:"time-field,omitempty"`
+	// TimeFormat is the Go reference layout of the time field, or unix for seconds since the epoch.
+	// Defaults to RFC 3339, and numeric json times are always read as seconds since the epoch.
+	TimeFormat string `json:"time-format,omitempty" yaml:"time-format,omitempty"`
+	// Tail limits the lines read to the last N lines of the file
+	Tail int `json:"tail,omitempty" yaml:"tail,omitempty"`
+	// Since only returns entries at or after this time, given as a duration before now, e.g. 24h,
+	// or an RFC 3339 timestamp
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+}
+
+func CreateLogsDomain(spec *LogsSpec) (types.Domain, error) {
+	if err := validateSpec(spec); err != nil {
+		return nil, err
+	}
+	return LogsDomain{Spec: spec}, nil
+}
+
+// GetResources returns the parsed entries of each log keyed by log name
+func (d LogsDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.readLogs(ctx)
+}
+
+// IsExecutable returns false; the domain only reads files.
+func (d LogsDomain) IsExecutable() bool { return false }
@@ -0,0 +1,88 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// NamedDomain is a constituent domain whose resources are keyed by name
+type NamedDomain struct {
+	Name   string
+	Domain types.Domain
+}
+
+// MultiDomain collects the resources of several domains into a single set of resources,
+// namespaced by the name of each domain
+type MultiDomain struct {
+	Domains []NamedDomain
+}
+
+func CreateMultiDomain(domains []NamedDomain) (types.Domain, error) {
+	if len(domains) == 0 {
+		return nil, errors.New("some domains must be specified")
+	}
+
+	var errs error
+	names := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if d.Name == "" {
+			errs = errors.Join(errs, errors.New("domain name cannot be empty"))
+		} else if names[d.Name] {
+			errs = errors.Join(errs, fmt.Errorf("domain name %s must be unique", d.Name))
+		}
+		names[d.Name] = true
+
+		if d.Domain == nil {
+			errs = errors.Join(errs, fmt.Errorf("domain %s is nil", d.Name))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return MultiDomain{Domains: domains}, nil
+}
+
+// GetResources collects the resources of every domain in parallel and returns them keyed by
+// domain name. Domains that fail are reported in the error and have empty resources.
+func (d MultiDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	results := make([]types.DomainResources, len(d.Domains))
+	errs := make([]error, len(d.Domains))
+
+	var wg sync.WaitGroup
+	for i, named := range d.Domains {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = named.Domain.GetResources(ctx)
+		}()
+	}
+	wg.Wait()
+
+	resources := make(types.DomainResources, len(d.Domains))
+	var err error
+	for i, named := range d.Domains {
+		if errs[i] != nil {
+			err = errors.Join(err, fmt.Errorf("domain %s: %w", named.Name, errs[i]))
+		}
+		if results[i] == nil {
+			// Assign empty data value for reporting purposes
+			results[i] = types.DomainResources{}
+		}
+		resources[named.Name] = map[string]interface{}(results[i])
+	}
+	return resources, err
+}
+
+// IsExecutable returns true if any of the domains are executable
+func (d MultiDomain) IsExecutable() bool {
+	for _, named := range d.Domains {
+		if named.Domain.IsExecutable() {
+			return true
+		}
+	}
+	return false
+}
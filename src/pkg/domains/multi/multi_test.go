@@ -0,0 +1,113 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*MultiDomain)(nil)
+
+type stubDomain struct {
+	resources  types.DomainResources
+	err        error
+	executable bool
+	// running counts the domains collecting resources at the same time
+	running *atomic.Int32
+	maxSeen *atomic.Int32
+}
+
+func (s stubDomain) GetResources(_ context.Context) (types.DomainResources, error) {
+	if s.running != nil {
+		n := s.running.Add(1)
+		defer s.running.Add(-1)
+		for {
+			seen := s.maxSeen.Load()
+			if n <= seen || s.maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return s.resources, s.err
+}
+
+func (s stubDomain) IsExecutable() bool { return s.executable }
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	domain, err := CreateMultiDomain([]NamedDomain{
+		{Name: "cluster", Domain: stubDomain{resources: types.DomainResources{"namespaces": []interface{}{"a", "b"}}}},
+		{Name: "cmdb", Domain: stubDomain{resources: types.DomainResources{"entries": []interface{}{"a"}}}},
+		{Name: "broken", Domain: stubDomain{err: errors.New("unreachable")}},
+	})
+	require.NoError(t, err)
+	require.False(t, domain.IsExecutable())
+
+	resources, err := domain.GetResources(context.Background())
+	require.EqualError(t, err, "domain broken: unreachable")
+	require.Equal(t, types.DomainResources{
+		"cluster": map[string]interface{}{"namespaces": []interface{}{"a", "b"}},
+		"cmdb":    map[string]interface{}{"entries": []interface{}{"a"}},
+		"broken":  map[string]interface{}{},
+	}, resources)
+}
+
+func TestGetResourcesParallel(t *testing.T) {
+	t.Parallel()
+
+	var running, maxSeen atomic.Int32
+	domains := make([]NamedDomain, 0, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		domains = append(domains, NamedDomain{Name: name, Domain: stubDomain{running: &running, maxSeen: &maxSeen}})
+	}
+	domain, err := CreateMultiDomain(domains)
+	require.NoError(t, err)
+
+	_, err = domain.GetResources(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, maxSeen.Load(), int32(1))
+}
+
+func TestIsExecutable(t *testing.T) {
+	t.Parallel()
+
+	domain, err := CreateMultiDomain([]NamedDomain{
+		{Name: "a", Domain: stubDomain{}},
+		{Name: "b", Domain: stubDomain{executable: true}},
+	})
+	require.NoError(t, err)
+	require.True(t, domain.IsExecutable())
+}
+
+func TestCreateMultiDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		domains []NamedDomain
+		wantErr bool
+	}{
+		{name: "no domains", domains: nil, wantErr: true},
+		{name: "valid", domains: []NamedDomain{{Name: "a", Domain: stubDomain{}}, {Name: "b", Domain: stubDomain{}}}},
+		{name: "missing name", domains: []NamedDomain{{Domain: stubDomain{}}}, wantErr: true},
+		{name: "duplicate name", domains: []NamedDomain{{Name: "a", Domain: stubDomain{}}, {Name: "a", Domain: stubDomain{}}}, wantErr: true},
+		{name: "nil domain", domains: []NamedDomain{{Name: "a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateMultiDomain(tt.domains)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateMultiDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package oci
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// annotationRefName is the OCI annotation naming an image within a layout, usually its tag
+const annotationRefName = "org.opencontainers.image.ref.name"
+
+// defaultPlatform matches the default of docker and crane when pulling from an index
+var defaultPlatform = v1.Platform{OS: "linux", Architecture: "amd64"}
+
+func (d OciDomain) inspectImages(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	resources := make(types.DomainResources, len(d.Spec.Images))
+	var errs error
+	for _, image := range d.Spec.Images {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := inspectImage(ctx, image, workDir)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("image %s: %w", image.Name, err))
+		}
+		if result == nil {
+			// Assign empty data value for reporting purposes
+			result = map[string]interface{}{}
+		}
+		resources[image.Name] = result
+	}
+	return resources, errs
+}
+
+// inspectImage returns the digest, manifest, config and selected files of the image.
+// Files that cannot be read are reported in the error alongside the rest of the result.
+func inspectImage(ctx context.Context, image Image, workDir string) (map[string]interface{}, error) {
+	img, err := loadImage(ctx, image, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("error computing image digest: %w", err)
+	}
+	rawManifest, err := img.RawManifest()
+	if err != nil {
+		return nil, fmt.Errorf("error reading image manifest: %w", err)
+	}
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(rawManifest, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing image manifest: %w", err)
+	}
+	rawConfig, err := img.RawConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("error reading image config: %w", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		return nil, fmt.Errorf("error parsing image config: %w", err)
+	}
+
+	files := make(map[string]interface{})
+	if len(image.Files) > 0 {
+		maxBytes := image.MaxFileBytes
+		if maxBytes == 0 {
+			maxBytes = defaultMaxFileBytes
+		}
+		files, err = readFiles(img, image.Files, int64(maxBytes))
+	}
+
+	return map[string]interface{}{
+		"digest":   digest.String(),
+		"manifest": manifest,
+		"config":   config,
+		"files":    files,
+	}, err
+}
+
+// loadImage opens the image from its layout, archive or registry
+func loadImage(ctx context.Context, image Image, workDir string) (v1.Image, error) {
+	platform := defaultPlatform
+	if image.Platform != "" {
+		p, err := v1.ParsePlatform(image.Platform)
+		if err != nil {
+			return nil, err
+		}
+		platform = *p
+	}
+
+	switch {
+	case image.Layout != "":
+		p, err := layout.FromPath(resolvePath(image.Layout, workDir))
+		if err != nil {
+			return nil, fmt.Errorf("error opening image layout: %w", err)
+		}
+		index, err := p.ImageIndex()
+		if err != nil {
+			return nil, fmt.Errorf("error reading image layout index: %w", err)
+		}
+		return selectFromIndex(index, image.Ref, platform)
+	case image.Archive != "":
+		var tag *name.Tag
+		if image.Ref != "" {
+			t, err := name.NewTag(image.Ref)
+			if err != nil {
+				return nil, err
+			}
+			tag = &t
+		}
+		img, err := tarball.ImageFromPath(resolvePath(image.Archive, workDir), tag)
+		if err != nil {
+			return nil, fmt.Errorf("error opening image archive: %w", err)
+		}
+		return img, nil
+	default:
+		opts := make([]name.Option, 0)
+		if image.Insecure {
+			opts = append(opts, name.Insecure)
+		}
+		ref, err := name.ParseReference(image.Reference, opts...)
+		if err != nil {
+			return nil, err
+		}
+		img, err := remote.Image(ref,
+			remote.WithContext(ctx),
+			remote.WithAuthFromKeychain(authn.DefaultKeychain),
+			remote.WithPlatform(platform),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching image %s: %w", image.Reference, err)
+		}
+		return img, nil
+	}
+}
+
+// selectFromIndex returns the image in a layout index matching ref, descending into
+// multi-platform indexes by platform. An empty ref matches when the index has a single entry.
+func selectFromIndex(index v1.ImageIndex, ref string, platform v1.Platform) (v1.Image, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]v1.Descriptor, 0)
+	for _, desc := range manifest.Manifests {
+		if ref == "" || desc.Digest.String() == ref || desc.Annotations[annotationRefName] == ref {
+			candidates = append(candidates, desc)
+		}
+	}
+	switch {
+	case len(candidates) == 0:
+		return nil, fmt.Errorf("no image matching ref %q", ref)
+	case len(candidates) > 1 && ref == "":
+		return nil, errors.New("the image layout contains multiple images, ref must be specified")
+	case len(candidates) > 1:
+		return nil, fmt.Errorf("ref %q matches multiple images", ref)
+	}
+
+	desc := candidates[0]
+	switch {
+	case desc.MediaType.IsImage():
+		return index.Image(desc.Digest)
+	case desc.MediaType.IsIndex():
+		child, err := index.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return selectPlatform(child, platform)
+	default:
+		return nil, fmt.Errorf("unsupported media type %s", desc.MediaType)
+	}
+}
+
+// selectPlatform returns the image for the platform from a multi-platform index
+func selectPlatform(index v1.ImageIndex, platform v1.Platform) (v1.Image, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.MediaType.IsImage() && desc.Platform != nil && desc.Platform.Satisfies(platform) {
+			return index.Image(desc.Digest)
+		}
+	}
+	return nil, fmt.Errorf("no image for platform %s", platform.String())
+}
+
+// readFiles returns the contents of the regular files in the flattened image filesystem
+// matching any of the patterns, keyed by absolute path
+func readFiles(img v1.Image, patterns []string, maxBytes int64) (map[string]interface{}, error) {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		normalized = append(normalized, path.Join("/", pattern))
+	}
+
+	fs := mutate.Extract(img)
+	defer fs.Close()
+
+	files := make(map[string]interface{})
+	var errs error
+	tr := tar.NewReader(fs)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return files, errors.Join(errs, fmt.Errorf("error reading image filesystem: %w", err))
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		filePath := path.Join("/", header.Name)
+		if !matchesAny(filePath, normalized) {
+			continue
+		}
+		if header.Size > maxBytes {
+			errs = errors.Join(errs, fmt.Errorf("file %s exceeds %d bytes", filePath, maxBytes))
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return files, errors.Join(errs, fmt.Errorf("error reading %s: %w", filePath, err))
+		}
+		files[filePath] = string(b)
+	}
+	return files, errs
+}
+
+func matchesAny(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated when the domain is created
+		if matched, _ := path.Match(pattern, filePath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath joins relative paths to the working directory
+func resolvePath(p, workDir string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(workDir, p)
+}
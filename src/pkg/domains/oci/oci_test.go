@@ -0,0 +1,182 @@
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*OciDomain)(nil)
+
+// testImage builds a small image running as the given user
+func testImage(t *testing.T, user string) v1.Image {
+	t.Helper()
+
+	layer, err := crane.Layer(map[string][]byte{
+		"etc/passwd":           []byte("root:x:0:0:root:/root:/bin/sh\napp:x:1000:1000::/home/app:/sbin/nologin\n"),
+		"etc/ssl/openssl.cnf":  []byte("[req]\n"),
+		"usr/share/large.bin":  []byte(strings.Repeat("x", 2048)),
+		"usr/share/readme.txt": []byte("readme"),
+	})
+	require.NoError(t, err)
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+	img, err = mutate.Config(img, v1.Config{
+		User:         user,
+		Entrypoint:   []string{"/app"},
+		Env:          []string{"PATH=/usr/bin"},
+		ExposedPorts: map[string]struct{}{"8080/tcp": {}},
+		Labels:       map[string]string{"org.opencontainers.image.source": "https://example.com/app"},
+	})
+	require.NoError(t, err)
+	return img
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	nonRoot := testImage(t, "1000")
+	root := testImage(t, "0")
+
+	// Layout with two tagged images and a multi-platform index
+	p, err := layout.Write(filepath.Join(dir, "layout"), empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(nonRoot, layout.WithAnnotations(map[string]string{annotationRefName: "1.0.0"})))
+	require.NoError(t, p.AppendImage(root, layout.WithAnnotations(map[string]string{annotationRefName: "0.9.0"})))
+	multi := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: root, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: nonRoot, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	require.NoError(t, p.AppendIndex(multi, layout.WithAnnotations(map[string]string{annotationRefName: "multi"})))
+
+	// Docker archive
+	tag, err := name.NewTag("example.com/app:1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, tarball.WriteToFile(filepath.Join(dir, "app.tar"), tag, nonRoot))
+
+	// Registry
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	reference := strings.TrimPrefix(server.URL, "http://") + "/app:1.0.0"
+	remoteRef, err := name.ParseReference(reference)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(remoteRef, nonRoot))
+
+	nonRootDigest, err := nonRoot.Digest()
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	tests := []struct {
+		name     string
+		image    Image
+		wantUser string
+		wantErr  bool
+	}{
+		{name: "layout by ref name", image: Image{Layout: "layout", Ref: "1.0.0"}, wantUser: "1000"},
+		{name: "layout by digest", image: Image{Layout: "layout", Ref: nonRootDigest.String()}, wantUser: "1000"},
+		{name: "layout default platform", image: Image{Layout: "layout", Ref: "multi"}, wantUser: "0"},
+		{name: "layout platform", image: Image{Layout: "layout", Ref: "multi", Platform: "linux/arm64"}, wantUser: "1000"},
+		{name: "layout missing platform", image: Image{Layout: "layout", Ref: "multi", Platform: "linux/s390x"}, wantErr: true},
+		{name: "layout without ref", image: Image{Layout: "layout"}, wantErr: true},
+		{name: "layout unknown ref", image: Image{Layout: "layout", Ref: "3.0.0"}, wantErr: true},
+		{name: "archive", image: Image{Archive: "app.tar"}, wantUser: "1000"},
+		{name: "archive by tag", image: Image{Archive: filepath.Join(dir, "app.tar"), Ref: "example.com/app:1.0.0"}, wantUser: "1000"},
+		{name: "registry", image: Image{Reference: reference}, wantUser: "1000"},
+		{name: "missing archive", image: Image{Archive: "missing.tar"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.image.Name = "app"
+			domain, err := CreateOciDomain(&OciSpec{Images: []Image{tt.image}})
+			require.NoError(t, err)
+
+			resources, err := domain.GetResources(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			result, ok := resources["app"].(map[string]interface{})
+			require.True(t, ok)
+			if tt.wantErr {
+				require.Empty(t, result)
+				return
+			}
+
+			config := result["config"].(map[string]interface{})["config"].(map[string]interface{})
+			require.Equal(t, tt.wantUser, config["User"])
+			require.Equal(t, map[string]interface{}{"8080/tcp": map[string]interface{}{}}, config["ExposedPorts"])
+			require.Contains(t, result["manifest"], "layers")
+			require.True(t, strings.HasPrefix(result["digest"].(string), "sha256:"))
+			require.Empty(t, result["files"])
+		})
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	t.Parallel()
+
+	img := testImage(t, "1000")
+
+	files, err := readFiles(img, []string{"/etc/passwd", "etc/ssl/*.cnf", "/usr/share/*"}, 1024)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "/usr/share/large.bin")
+	require.Equal(t, map[string]interface{}{
+		"/etc/passwd":           "root:x:0:0:root:/root:/bin/sh\napp:x:1000:1000::/home/app:/sbin/nologin\n",
+		"/etc/ssl/openssl.cnf":  "[req]\n",
+		"/usr/share/readme.txt": "readme",
+	}, files)
+
+	files, err = readFiles(img, []string{"/etc/*"}, 1024)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestCreateOciDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *OciSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no images", spec: &OciSpec{}, wantErr: true},
+		{name: "valid", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "layout", Files: []string{"/etc/*"}}}}},
+		{name: "missing name", spec: &OciSpec{Images: []Image{{Layout: "layout"}}}, wantErr: true},
+		{name: "duplicate name", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "a"}, {Name: "a", Layout: "b"}}}, wantErr: true},
+		{name: "no source", spec: &OciSpec{Images: []Image{{Name: "a"}}}, wantErr: true},
+		{name: "multiple sources", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "a", Archive: "a.tar"}}}, wantErr: true},
+		{name: "ref with reference", spec: &OciSpec{Images: []Image{{Name: "a", Reference: "nginx:1.27", Ref: "latest"}}}, wantErr: true},
+		{name: "insecure with layout", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "a", Insecure: true}}}, wantErr: true},
+		{name: "invalid platform", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "a", Platform: "linux/amd64/v2/x"}}}, wantErr: true},
+		{name: "invalid pattern", spec: &OciSpec{Images: []Image{{Name: "a", Layout: "a", Files: []string{"/etc/["}}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateOciDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateOciDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
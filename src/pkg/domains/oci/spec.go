@@ -0,0 +1,81 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// validateSpec validates the entire spec and may return multiple errors
+func validateSpec(spec *OciSpec) (errs error) {
+	if spec == nil {
+		return errors.New("spec is required")
+	}
+	if len(spec.Images) == 0 {
+		return errors.New("some images must be specified")
+	}
+
+	names := make(map[string]bool, len(spec.Images))
+	for _, image := range spec.Images {
+		if image.Name == "" {
+			errs = errors.Join(errs, errors.New("image name cannot be empty"))
+		} else if names[image.Name] {
+			errs = errors.Join(errs, fmt.Errorf("image name %s must be unique", image.Name))
+		}
+		names[image.Name] = true
+
+		if err := image.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("image %s: %w", image.Name, err))
+		}
+	}
+	return errs
+}
+
+func (i Image) validate() (errs error) {
+	sources := 0
+	for _, s := range []string{i.Layout, i.Archive, i.Reference} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		errs = errors.Join(errs, errors.New("exactly one of layout, archive, or reference must be specified"))
+	}
+
+	if i.Reference != "" {
+		if i.Ref != "" {
+			errs = errors.Join(errs, errors.New("ref cannot be used with reference, include the tag or digest in the reference"))
+		}
+		if _, err := name.ParseReference(i.Reference); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid reference: %w", err))
+		}
+	} else if i.Insecure {
+		errs = errors.Join(errs, errors.New("insecure can only be used with reference"))
+	}
+
+	if i.Archive != "" && i.Ref != "" {
+		if _, err := name.NewTag(i.Ref); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid archive ref: %w", err))
+		}
+	}
+
+	if i.Platform != "" {
+		if _, err := v1.ParsePlatform(i.Platform); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid platform: %w", err))
+		}
+	}
+
+	for _, pattern := range i.Files {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid file pattern %s: %w", pattern, err))
+		}
+	}
+
+	if i.MaxFileBytes < 0 {
+		errs = errors.Join(errs, errors.New("max-file-bytes cannot be negative"))
+	}
+	return errs
+}
@@ -0,0 +1,59 @@
+package oci
+
+import (
+	"context"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// defaultMaxFileBytes limits the size of each file returned from an image
+const defaultMaxFileBytes = 1024 * 1024
+
+// OciDomain reads container images from an OCI image layout, a docker-archive tarball
+// or a registry, without running them.
+type OciDomain struct {
+	Spec *OciSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// OciSpec is the user-defined specification of images to inspect
+type OciSpec struct {
+	Images []Image `json:"images" yaml:"images"`
+}
+
+// Image is a single image to inspect. Exactly one of Layout, Archive or Reference must be specified.
+type Image struct {
+	// Name is the key of the image in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Layout is the path to an OCI image layout directory
+	Layout string `json:"layout,omitempty" yaml:"layout,omitempty"`
+	// Archive is the path to a docker-archive tarball, as written by `docker save`
+	Archive string `json:"archive,omitempty" yaml:"archive,omitempty"`
+	// Reference is an image in a registry, e.g. ghcr.io/org/app:1.0.0
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+	// Ref selects the image within a layout, by its org.opencontainers.image.ref.name annotation
+	// or digest, or within an archive by its tag. Not required if the source contains a single image.
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// Platform selects the image from a multi-platform index, e.g. linux/arm64. Defaults to linux/amd64.
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+	// Insecure allows connecting to the registry over plain HTTP
+	Insecure bool `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+	// Files are glob patterns of file paths to read from the image filesystem, e.g. /etc/passwd or /etc/ssl/*.cnf
+	Files []string `json:"files,omitempty" yaml:"files,omitempty"`
+	// MaxFileBytes is the largest file that is returned, defaults to 1MiB
+	MaxFileBytes int `json:"max-file-bytes,omitempty" yaml:"max-file-bytes,omitempty"`
+}
+
+func CreateOciDomain(spec *OciSpec) (types.Domain, error) {
+	if err := validateSpec(spec); err != nil {
+		return nil, err
+	}
+	return OciDomain{Spec: spec}, nil
+}
+
+// GetResources returns the config, manifest and selected files of each image keyed by image name
+func (d OciDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.inspectImages(ctx)
+}
+
+// IsExecutable returns false; images are read but never run.
+func (d OciDomain) IsExecutable() bool { return false }
@@ -0,0 +1,353 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// acceptHeader requests the text exposition format, which is served by every exporter
+const acceptHeader = "text/plain;version=0.0.4;q=1,*/*;q=0.1"
+
+func (d PrometheusDomain) collect(ctx context.Context) (types.DomainResources, error) {
+	client := &http.Client{Timeout: d.timeout}
+
+	resources := make(types.DomainResources, len(d.Spec.Scrapes)+len(d.Spec.Queries))
+	var errs error
+	for _, scrape := range d.Spec.Scrapes {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		families, err := d.scrape(ctx, client, scrape)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("scrape %s: %w", scrape.Name, err))
+			// Assign empty data value for reporting purposes
+			families = map[string]interface{}{}
+		}
+		resources[scrape.Name] = families
+	}
+
+	for _, query := range d.Spec.Queries {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := d.query(ctx, client, query, time.Now())
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("query %s: %w", query.Name, err))
+			// Assign empty data value for reporting purposes
+			result = map[string]interface{}{}
+		}
+		resources[query.Name] = result
+	}
+	return resources, errs
+}
+
+// scrape reads the metrics endpoint and returns its metric families keyed by name
+func (d PrometheusDomain) scrape(ctx context.Context, client *http.Client, scrape Scrape) (map[string]interface{}, error) {
+	req, err := d.newRequest(ctx, scrape.Url)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", acceptHeader)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var include map[string]bool
+	if len(scrape.Metrics) > 0 {
+		include = make(map[string]bool, len(scrape.Metrics))
+		for _, m := range scrape.Metrics {
+			include[m] = true
+		}
+	}
+
+	families := make(map[string]interface{})
+	decoder := expfmt.NewDecoder(resp.Body, expfmt.ResponseFormat(resp.Header))
+	for {
+		var mf dto.MetricFamily
+		err := decoder.Decode(&mf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metrics: %w", err)
+		}
+		if include != nil && !include[mf.GetName()] {
+			continue
+		}
+		families[mf.GetName()] = convertFamily(&mf)
+	}
+	return families, nil
+}
+
+// convertFamily returns the type, help and samples of the metric family. Counters, gauges and
+// untyped metrics have a single value per sample, while histograms and summaries have a count,
+// sum, and their buckets or quantiles.
+func convertFamily(mf *dto.MetricFamily) map[string]interface{} {
+	samples := make([]interface{}, 0, len(mf.GetMetric()))
+	for _, m := range mf.GetMetric() {
+		labels := make(map[string]interface{}, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		sample := map[string]interface{}{"labels": labels}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			sample["value"] = number(m.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			sample["value"] = number(m.GetGauge().GetValue())
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			quantiles := make(map[string]interface{}, len(s.GetQuantile()))
+			for _, q := range s.GetQuantile() {
+				quantiles[bound(q.GetQuantile())] = number(q.GetValue())
+			}
+			sample["count"] = float64(s.GetSampleCount())
+			sample["sum"] = number(s.GetSampleSum())
+			sample["quantiles"] = quantiles
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			h := m.GetHistogram()
+			buckets := make(map[string]interface{}, len(h.GetBucket()))
+			for _, b := range h.GetBucket() {
+				buckets[bound(b.GetUpperBound())] = float64(b.GetCumulativeCount())
+			}
+			sample["count"] = float64(h.GetSampleCount())
+			sample["sum"] = number(h.GetSampleSum())
+			sample["buckets"] = buckets
+		default:
+			sample["value"] = number(m.GetUntyped().GetValue())
+		}
+
+		if m.TimestampMs != nil {
+			sample["timestamp"] = time.UnixMilli(m.GetTimestampMs()).UTC().Format(time.RFC3339Nano)
+		}
+		samples = append(samples, sample)
+	}
+
+	return map[string]interface{}{
+		"type":    strings.ToLower(mf.GetType().String()),
+		"help":    mf.GetHelp(),
+		"samples": samples,
+	}
+}
+
+// apiResponse is the envelope of every Prometheus HTTP API response
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+	Warnings  []string        `json:"warnings"`
+}
+
+// queryData is the data of an instant or range query response
+type queryData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// series is a single element of a vector or matrix result
+type series struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+	Values [][]interface{}   `json:"values"`
+}
+
+// query runs the PromQL query at now, or over the range ending at now, and returns the
+// result type and typed result
+func (d PrometheusDomain) query(ctx context.Context, client *http.Client, query Query, now time.Time) (map[string]interface{}, error) {
+	params := url.Values{"query": {query.Query}}
+	endpoint := "/api/v1/query"
+	if query.Range != "" {
+		// durations are validated when the domain is created
+		rng, _ := time.ParseDuration(query.Range)
+		step := defaultStep
+		if query.Step != "" {
+			step, _ = time.ParseDuration(query.Step)
+		}
+		endpoint = "/api/v1/query_range"
+		params.Set("start", formatTime(now.Add(-rng)))
+		params.Set("end", formatTime(now))
+		params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	} else {
+		params.Set("time", formatTime(now))
+	}
+
+	req, err := d.newRequest(ctx, strings.TrimSuffix(d.Spec.Url, "/")+endpoint+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("error decoding response with status %s: %w", resp.Status, err)
+	}
+	if apiResp.Status != "success" {
+		return nil, fmt.Errorf("%s: %s", apiResp.ErrorType, apiResp.Error)
+	}
+
+	var data queryData
+	if err := json.Unmarshal(apiResp.Data, &data); err != nil {
+		return nil, fmt.Errorf("error decoding query data: %w", err)
+	}
+	result, err := convertResult(data)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]interface{}, 0, len(apiResp.Warnings))
+	for _, w := range apiResp.Warnings {
+		warnings = append(warnings, w)
+	}
+	return map[string]interface{}{
+		"type":     data.ResultType,
+		"result":   result,
+		"warnings": warnings,
+	}, nil
+}
+
+// convertResult types the values of a query result. Vectors and matrices are lists of series
+// with their labels, scalars and strings are a single sample.
+func convertResult(data queryData) (interface{}, error) {
+	switch data.ResultType {
+	case "vector", "matrix":
+		var raw []series
+		if err := json.Unmarshal(data.Result, &raw); err != nil {
+			return nil, fmt.Errorf("error decoding %s result: %w", data.ResultType, err)
+		}
+		result := make([]interface{}, 0, len(raw))
+		for _, s := range raw {
+			labels := make(map[string]interface{}, len(s.Metric))
+			for k, v := range s.Metric {
+				labels[k] = v
+			}
+			item := map[string]interface{}{"labels": labels}
+			if data.ResultType == "vector" {
+				sample, err := convertSample(s.Value, true)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range sample {
+					item[k] = v
+				}
+			} else {
+				values := make([]interface{}, 0, len(s.Values))
+				for _, v := range s.Values {
+					sample, err := convertSample(v, true)
+					if err != nil {
+						return nil, err
+					}
+					values = append(values, sample)
+				}
+				item["values"] = values
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	case "scalar", "string":
+		var raw []interface{}
+		if err := json.Unmarshal(data.Result, &raw); err != nil {
+			return nil, fmt.Errorf("error decoding %s result: %w", data.ResultType, err)
+		}
+		return convertSample(raw, data.ResultType == "scalar")
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", data.ResultType)
+	}
+}
+
+// convertSample converts a [timestamp, "value"] pair to its timestamp and value, parsing the
+// value as a number unless it is the result of a string query
+func convertSample(pair []interface{}, numeric bool) (map[string]interface{}, error) {
+	if len(pair) != 2 {
+		return nil, fmt.Errorf("invalid sample %v", pair)
+	}
+	ts, ok := pair[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("invalid sample timestamp %v", pair[0])
+	}
+	raw, ok := pair[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid sample value %v", pair[1])
+	}
+
+	var value interface{} = raw
+	if numeric {
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q: %w", raw, err)
+		}
+		value = number(f)
+	}
+	return map[string]interface{}{
+		"timestamp": time.UnixMilli(int64(math.Round(ts * 1000))).UTC().Format(time.RFC3339Nano),
+		"value":     value,
+	}, nil
+}
+
+func (d PrometheusDomain) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range d.Spec.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// number returns the value as a float, or as the strings NaN, +Inf or -Inf, which cannot
+// be represented in JSON
+func number(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return f
+}
+
+// bound formats a bucket upper bound or quantile the same way as the exposition format
+func bound(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
@@ -0,0 +1,222 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*PrometheusDomain)(nil)
+
+const metrics = `# HELP up Whether the target is up.
+# TYPE up gauge
+up{job="app"} 1
+# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200"} 1027 1700000000000
+http_requests_total{code="500"} 3
+# HELP request_seconds Request latency.
+# TYPE request_seconds histogram
+request_seconds_bucket{le="0.1"} 90
+request_seconds_bucket{le="1"} 99
+request_seconds_bucket{le="+Inf"} 100
+request_seconds_sum 12.5
+request_seconds_count 100
+# HELP gc_seconds GC pauses.
+# TYPE gc_seconds summary
+gc_seconds{quantile="0.5"} 0.01
+gc_seconds{quantile="0.99"} NaN
+gc_seconds_sum 1.5
+gc_seconds_count 40
+`
+
+// server serves the metrics and a stand-in of the Prometheus query API
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, metrics)
+	})
+	mux.HandleFunc("/api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("query") {
+		case `up{job="app"}`:
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"app"},"value":[1700000000.5,"1"]}]}}`)
+		case "scalar(1)":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"+Inf"]},"warnings":["partial data"]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+		}
+	})
+	mux.HandleFunc("/api/v1/query_range", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("step") != "300" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"unexpected step"}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"app"},"values":[[1700000000,"0.99"],[1700000300,"1"]]}]}}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	srv := server(t)
+	domain, err := CreatePrometheusDomain(&PrometheusSpec{
+		Url:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Scrapes: []Scrape{
+			{Name: "all", Url: srv.URL + "/metrics"},
+			{Name: "filtered", Url: srv.URL + "/metrics", Metrics: []string{"up"}},
+			{Name: "missing", Url: srv.URL + "/missing"},
+		},
+		Queries: []Query{
+			{Name: "up", Query: `up{job="app"}`},
+			{Name: "scalar", Query: "scalar(1)"},
+			{Name: "availability", Query: `avg_over_time(up{job="app"}[5m])`, Range: "1h", Step: "5m"},
+			{Name: "invalid", Query: "up{"},
+		},
+	})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(context.Background())
+	require.ErrorContains(t, err, "scrape missing: unexpected status 404 Not Found")
+	require.ErrorContains(t, err, "query invalid: bad_data: parse error")
+	require.Equal(t, map[string]interface{}{}, resources["missing"])
+	require.Equal(t, map[string]interface{}{}, resources["invalid"])
+
+	all := resources["all"].(map[string]interface{})
+	require.Len(t, all, 4)
+	require.Equal(t, map[string]interface{}{
+		"type": "counter",
+		"help": "Total requests.",
+		"samples": []interface{}{
+			map[string]interface{}{"labels": map[string]interface{}{"code": "200"}, "value": 1027.0, "timestamp": "2023-11-14T22:13:20Z"},
+			map[string]interface{}{"labels": map[string]interface{}{"code": "500"}, "value": 3.0},
+		},
+	}, all["http_requests_total"])
+	require.Equal(t, map[string]interface{}{
+		"labels":  map[string]interface{}{},
+		"count":   100.0,
+		"sum":     12.5,
+		"buckets": map[string]interface{}{"0.1": 90.0, "1": 99.0, "+Inf": 100.0},
+	}, all["request_seconds"].(map[string]interface{})["samples"].([]interface{})[0])
+	require.Equal(t, map[string]interface{}{
+		"labels":    map[string]interface{}{},
+		"count":     40.0,
+		"sum":       1.5,
+		"quantiles": map[string]interface{}{"0.5": 0.01, "0.99": "NaN"},
+	}, all["gc_seconds"].(map[string]interface{})["samples"].([]interface{})[0])
+
+	filtered := resources["filtered"].(map[string]interface{})
+	require.Len(t, filtered, 1)
+	require.Equal(t, "gauge", filtered["up"].(map[string]interface{})["type"])
+
+	require.Equal(t, map[string]interface{}{
+		"type": "vector",
+		"result": []interface{}{
+			map[string]interface{}{
+				"labels":    map[string]interface{}{"__name__": "up", "job": "app"},
+				"timestamp": "2023-11-14T22:13:20.5Z",
+				"value":     1.0,
+			},
+		},
+		"warnings": []interface{}{},
+	}, resources["up"])
+
+	require.Equal(t, map[string]interface{}{
+		"type":     "scalar",
+		"result":   map[string]interface{}{"timestamp": "2023-11-14T22:13:20Z", "value": "+Inf"},
+		"warnings": []interface{}{"partial data"},
+	}, resources["scalar"])
+
+	availability := resources["availability"].(map[string]interface{})
+	require.Equal(t, "matrix", availability["type"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"labels": map[string]interface{}{"job": "app"},
+			"values": []interface{}{
+				map[string]interface{}{"timestamp": "2023-11-14T22:13:20Z", "value": 0.99},
+				map[string]interface{}{"timestamp": "2023-11-14T22:18:20Z", "value": 1.0},
+			},
+		},
+	}, availability["result"])
+}
+
+func TestQueryRange(t *testing.T) {
+	t.Parallel()
+
+	var params map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params = map[string]string{"path": r.URL.Path}
+		for k := range r.URL.Query() {
+			params[k] = r.URL.Query().Get(k)
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	domain, err := validateSpec(&PrometheusSpec{Url: srv.URL + "/", Queries: []Query{{Name: "q", Query: "up", Range: "1h"}}})
+	require.NoError(t, err)
+
+	now := time.Unix(1700003600, 0)
+	_, err = domain.query(context.Background(), http.DefaultClient, domain.Spec.Queries[0], now)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"path":  "/api/v1/query_range",
+		"query": "up",
+		"start": "1700000000",
+		"end":   "1700003600",
+		"step":  "60",
+	}, params)
+}
+
+func TestCreatePrometheusDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *PrometheusSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no scrapes or queries", spec: &PrometheusSpec{}, wantErr: true},
+		{name: "valid scrape", spec: &PrometheusSpec{Scrapes: []Scrape{{Name: "a", Url: "http://app:8080/metrics"}}}},
+		{name: "valid query", spec: &PrometheusSpec{Url: "http://prometheus:9090", Queries: []Query{{Name: "a", Query: "up", Range: "1h", Step: "1m"}}}},
+		{name: "query without url", spec: &PrometheusSpec{Queries: []Query{{Name: "a", Query: "up"}}}, wantErr: true},
+		{name: "invalid url", spec: &PrometheusSpec{Url: "prometheus:9090", Queries: []Query{{Name: "a", Query: "up"}}}, wantErr: true},
+		{name: "invalid scrape url", spec: &PrometheusSpec{Scrapes: []Scrape{{Name: "a", Url: "ftp://app/metrics"}}}, wantErr: true},
+		{name: "duplicate name", spec: &PrometheusSpec{Url: "http://prometheus:9090", Scrapes: []Scrape{{Name: "a", Url: "http://app/metrics"}}, Queries: []Query{{Name: "a", Query: "up"}}}, wantErr: true},
+		{name: "empty query", spec: &PrometheusSpec{Url: "http://prometheus:9090", Queries: []Query{{Name: "a"}}}, wantErr: true},
+		{name: "step without range", spec: &PrometheusSpec{Url: "http://prometheus:9090", Queries: []Query{{Name: "a", Query: "up", Step: "1m"}}}, wantErr: true},
+		{name: "invalid range", spec: &PrometheusSpec{Url: "http://prometheus:9090", Queries: []Query{{Name: "a", Query: "up", Range: "1d"}}}, wantErr: true},
+		{name: "invalid timeout", spec: &PrometheusSpec{Timeout: "soon", Scrapes: []Scrape{{Name: "a", Url: "http://app/metrics"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreatePrometheusDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreatePrometheusDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// validateSpec validates the entire spec and may return multiple errors
+func validateSpec(spec *PrometheusSpec) (domain PrometheusDomain, errs error) {
+	if spec == nil {
+		return domain, errors.New("spec is required")
+	}
+	if len(spec.Scrapes) == 0 && len(spec.Queries) == 0 {
+		return domain, errors.New("some scrapes or queries must be specified")
+	}
+
+	domain = PrometheusDomain{Spec: spec, timeout: defaultTimeout}
+	if spec.Timeout != "" {
+		timeout, err := time.ParseDuration(spec.Timeout)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid timeout: %w", err))
+		} else if timeout <= 0 {
+			errs = errors.Join(errs, errors.New("timeout must be positive"))
+		}
+		domain.timeout = timeout
+	}
+
+	if len(spec.Queries) > 0 {
+		if spec.Url == "" {
+			errs = errors.Join(errs, errors.New("url is required for queries"))
+		} else if err := validateUrl(spec.Url); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid url: %w", err))
+		}
+	}
+
+	// scrapes and queries share the same resources, so names must be unique across both
+	names := make(map[string]bool, len(spec.Scrapes)+len(spec.Queries))
+	checkName := func(kind, name string) {
+		if name == "" {
+			errs = errors.Join(errs, fmt.Errorf("%s name cannot be empty", kind))
+		} else if names[name] {
+			errs = errors.Join(errs, fmt.Errorf("%s name %s must be unique", kind, name))
+		}
+		names[name] = true
+	}
+
+	for _, scrape := range spec.Scrapes {
+		checkName("scrape", scrape.Name)
+		if scrape.Url == "" {
+			errs = errors.Join(errs, fmt.Errorf("scrape %s: url cannot be empty", scrape.Name))
+		} else if err := validateUrl(scrape.Url); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("scrape %s: invalid url: %w", scrape.Name, err))
+		}
+	}
+
+	for _, query := range spec.Queries {
+		checkName("query", query.Name)
+		if err := query.validate(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("query %s: %w", query.Name, err))
+		}
+	}
+	return domain, errs
+}
+
+func (q Query) validate() (errs error) {
+	if q.Query == "" {
+		errs = errors.Join(errs, errors.New("query cannot be empty"))
+	}
+	if q.Range != "" {
+		if d, err := time.ParseDuration(q.Range); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid range: %w", err))
+		} else if d <= 0 {
+			errs = errors.Join(errs, errors.New("range must be positive"))
+		}
+	}
+	if q.Step != "" {
+		if q.Range == "" {
+			errs = errors.Join(errs, errors.New("step can only be used with range"))
+		}
+		if d, err := time.ParseDuration(q.Step); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid step: %w", err))
+		} else if d <= 0 {
+			errs = errors.Join(errs, errors.New("step must be positive"))
+		}
+	}
+	return errs
+}
+
+func validateUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return errors.New("host cannot be empty")
+	}
+	return nil
+}
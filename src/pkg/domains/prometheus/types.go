@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+const (
+	// defaultTimeout limits each scrape or query
+	defaultTimeout = 30 * time.Second
+	// defaultStep is the resolution of range queries
+	defaultStep = time.Minute
+)
+
+// PrometheusDomain scrapes metrics endpoints and runs PromQL queries against a Prometheus HTTP API
+type PrometheusDomain struct {
+	Spec    *PrometheusSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	timeout time.Duration
+}
+
+// PrometheusSpec is the user-defined specification of scrapes and queries. At least one
+// scrape or query must be specified.
+type PrometheusSpec struct {
+	// Url is the base URL of the Prometheus HTTP API, e.g. http://prometheus:9090, required if queries are specified
+	Url string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Scrapes are metrics endpoints to read
+	Scrapes []Scrape `json:"scrapes,omitempty" yaml:"scrapes,omitempty"`
+	// Queries are PromQL queries run against the Url
+	Queries []Query `json:"queries,omitempty" yaml:"queries,omitempty"`
+	// Headers are sent with every scrape and query, e.g. Authorization
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Timeout for each scrape or query, defaults to 30s
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Scrape is a single metrics endpoint in the Prometheus text exposition format
+type Scrape struct {
+	// Name is the key of the scrape in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Url of the metrics endpoint, e.g. http://app:8080/metrics
+	Url string `json:"url" yaml:"url"`
+	// Metrics are the names of the metric families returned, defaults to all
+	Metrics []string `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// Query is a single PromQL query, evaluated at the current time unless a range is specified
+type Query struct {
+	// Name is the key of the query in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Query is the PromQL expression
+	Query string `json:"query" yaml:"query"`
+	// Range runs a range query over this duration up to the current time, e.g. 24h
+	Range string `json:"range,omitempty" yaml:"range,omitempty"`
+	// Step is the resolution of a range query, defaults to 1m
+	Step string `json:"step,omitempty" yaml:"step,omitempty"`
+}
+
+func CreatePrometheusDomain(spec *PrometheusSpec) (types.Domain, error) {
+	return validateSpec(spec)
+}
+
+// GetResources returns the metric families of each scrape and the result of each query,
+// keyed by name
+func (d PrometheusDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.collect(ctx)
+}
+
+// IsExecutable returns false; the domain only reads metrics.
+func (d PrometheusDomain) IsExecutable() bool { return false }
@@ -0,0 +1,101 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	cdx "github.com/CycloneDX/cyclonedx-go"
+)
+
+// readCycloneDX parses a CycloneDX JSON or XML document. The original of an XML document
+// is returned in the CycloneDX JSON representation.
+func readCycloneDX(b []byte, format Format) (*document, error) {
+	fileFormat := cdx.BOMFileFormatJSON
+	if format == FormatCycloneDXXML {
+		fileFormat = cdx.BOMFileFormatXML
+	}
+
+	bom := new(cdx.BOM)
+	if err := cdx.NewBOMDecoder(bytes.NewReader(b), fileFormat).Decode(bom); err != nil {
+		return nil, fmt.Errorf("error parsing cyclonedx document: %w", err)
+	}
+
+	originalJSON := b
+	if fileFormat == cdx.BOMFileFormatXML {
+		var buf bytes.Buffer
+		if err := cdx.NewBOMEncoder(&buf, cdx.BOMFileFormatJSON).Encode(bom); err != nil {
+			return nil, fmt.Errorf("error converting cyclonedx document: %w", err)
+		}
+		originalJSON = buf.Bytes()
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(originalJSON, &original); err != nil {
+		return nil, fmt.Errorf("error parsing cyclonedx document: %w", err)
+	}
+
+	result := &document{
+		format:       normalizedCycloneDX,
+		specVersion:  bom.SpecVersion.String(),
+		packages:     make([]pkg, 0),
+		dependencies: make(map[string][]string),
+		original:     original,
+	}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		result.name = bom.Metadata.Component.Name
+	}
+
+	if bom.Components != nil {
+		result.packages = appendComponents(result.packages, *bom.Components)
+	}
+
+	if bom.Dependencies != nil {
+		for _, dep := range *bom.Dependencies {
+			if dep.Dependencies == nil {
+				continue
+			}
+			result.dependencies[dep.Ref] = append(result.dependencies[dep.Ref], *dep.Dependencies...)
+		}
+	}
+	return result, nil
+}
+
+// appendComponents flattens the component tree into the package list
+func appendComponents(packages []pkg, components []cdx.Component) []pkg {
+	for _, c := range components {
+		normalized := pkg{
+			id:       c.BOMRef,
+			name:     c.Name,
+			version:  c.Version,
+			purl:     c.PackageURL,
+			licenses: make([]string, 0),
+			hashes:   make(map[string]string),
+		}
+		if c.Supplier != nil {
+			normalized.supplier = c.Supplier.Name
+		}
+		if c.Licenses != nil {
+			for _, choice := range *c.Licenses {
+				switch {
+				case choice.Expression != "":
+					normalized.licenses = appendLicense(normalized.licenses, choice.Expression)
+				case choice.License != nil && choice.License.ID != "":
+					normalized.licenses = appendLicense(normalized.licenses, choice.License.ID)
+				case choice.License != nil:
+					normalized.licenses = appendLicense(normalized.licenses, choice.License.Name)
+				}
+			}
+		}
+		if c.Hashes != nil {
+			for _, hash := range *c.Hashes {
+				normalized.hashes[normalizeAlgorithm(string(hash.Algorithm))] = hash.Value
+			}
+		}
+		packages = append(packages, normalized)
+
+		if c.Components != nil {
+			packages = appendComponents(packages, *c.Components)
+		}
+	}
+	return packages
+}
@@ -0,0 +1,181 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Values of the normalized "format" field
+const (
+	normalizedSpdx      = "spdx"
+	normalizedCycloneDX = "cyclonedx"
+)
+
+// document is the format-independent representation of an SBOM
+type document struct {
+	format       string
+	specVersion  string
+	name         string
+	packages     []pkg
+	dependencies map[string][]string
+	original     map[string]interface{}
+}
+
+// pkg is a normalized package or component
+type pkg struct {
+	id       string
+	name     string
+	version  string
+	purl     string
+	supplier string
+	licenses []string
+	hashes   map[string]string
+}
+
+func (d SbomDomain) readSboms(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	resources := make(types.DomainResources, len(d.Spec.Sboms))
+	var errs error
+	for _, s := range d.Spec.Sboms {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		doc, err := readSbom(s, workDir)
+		if err != nil {
+			// Assign empty data value for reporting purposes
+			resources[s.Name] = map[string]interface{}{}
+			errs = errors.Join(errs, fmt.Errorf("sbom %s: %w", s.Name, err))
+			continue
+		}
+		resources[s.Name] = doc.toResource()
+	}
+	return resources, errs
+}
+
+func readSbom(s Sbom, workDir string) (*document, error) {
+	b, err := network.Fetch(s.Path, network.WithBaseDir(workDir))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", s.Path, err)
+	}
+
+	format := s.Format
+	if format == "" {
+		format, err = detectFormat(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch format {
+	case FormatSpdxJSON:
+		return readSpdx(b)
+	case FormatCycloneDXJSON, FormatCycloneDXXML:
+		return readCycloneDX(b, format)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// detectFormat identifies the document format from its content
+func detectFormat(b []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(b)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return FormatCycloneDXXML, nil
+	}
+
+	var probe struct {
+		SpdxVersion string `json:"spdxVersion"`
+		BomFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return "", fmt.Errorf("unable to detect sbom format: %w", err)
+	}
+	switch {
+	case probe.SpdxVersion != "":
+		return FormatSpdxJSON, nil
+	case probe.BomFormat == "CycloneDX":
+		return FormatCycloneDXJSON, nil
+	default:
+		return "", errors.New("unable to detect sbom format, the document is neither SPDX nor CycloneDX")
+	}
+}
+
+// toResource converts the document into the generic types passed to providers
+func (d *document) toResource() map[string]interface{} {
+	packages := make([]interface{}, 0, len(d.packages))
+	for _, p := range d.packages {
+		licenses := make([]interface{}, 0, len(p.licenses))
+		for _, l := range p.licenses {
+			licenses = append(licenses, l)
+		}
+		hashes := make(map[string]interface{}, len(p.hashes))
+		for alg, value := range p.hashes {
+			hashes[alg] = value
+		}
+		packages = append(packages, map[string]interface{}{
+			"id":       p.id,
+			"name":     p.name,
+			"version":  p.version,
+			"purl":     p.purl,
+			"supplier": p.supplier,
+			"licenses": licenses,
+			"hashes":   hashes,
+		})
+	}
+
+	dependencies := make(map[string]interface{}, len(d.dependencies))
+	for ref, dependsOn := range d.dependencies {
+		slices.Sort(dependsOn)
+		refs := make([]interface{}, 0, len(dependsOn))
+		for _, dep := range slices.Compact(dependsOn) {
+			refs = append(refs, dep)
+		}
+		dependencies[ref] = refs
+	}
+
+	return map[string]interface{}{
+		"format":       d.format,
+		"spec-version": d.specVersion,
+		"name":         d.name,
+		"packages":     packages,
+		"dependencies": dependencies,
+		"document":     d.original,
+	}
+}
+
+// normalizeAlgorithm gives the same name for hash algorithms in SPDX (SHA256) and CycloneDX (SHA-256)
+func normalizeAlgorithm(algorithm string) string {
+	algorithm = strings.ToLower(algorithm)
+	if strings.HasPrefix(algorithm, "sha-") {
+		return "sha" + strings.TrimPrefix(algorithm, "sha-")
+	}
+	return algorithm
+}
+
+// appendLicense adds a license if it is meaningful and not already present
+func appendLicense(licenses []string, license string) []string {
+	switch license {
+	case "", "NOASSERTION", "NONE":
+		return licenses
+	}
+	if slices.Contains(licenses, license) {
+		return licenses
+	}
+	return append(licenses, license)
+}
@@ -0,0 +1,227 @@
+package sbom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*SbomDomain)(nil)
+
+var cycloneDXPackages = []interface{}{
+	map[string]interface{}{
+		"id":       "pkg:generic/openssl@3.0.13",
+		"name":     "openssl",
+		"version":  "3.0.13",
+		"purl":     "pkg:generic/openssl@3.0.13",
+		"supplier": "OpenSSL",
+		"licenses": []interface{}{"Apache-2.0"},
+		"hashes":   map[string]interface{}{"sha256": "88525753f79d3bec27d2fa7c66aa0b92b3aa9498dafd93d7cfa4b3780cdae313"},
+	},
+	map[string]interface{}{
+		"id":       "pkg:generic/zlib@1.3.1",
+		"name":     "zlib",
+		"version":  "1.3.1",
+		"purl":     "pkg:generic/zlib@1.3.1",
+		"supplier": "",
+		"licenses": []interface{}{"Zlib"},
+		"hashes":   map[string]interface{}{"sha1": "f535367b1a11e2f9ac3bec723fb007fbc0d189e5"},
+	},
+	map[string]interface{}{
+		"id":       "pkg:generic/minizip@1.3.1",
+		"name":     "minizip",
+		"version":  "1.3.1",
+		"purl":     "pkg:generic/minizip@1.3.1",
+		"supplier": "",
+		"licenses": []interface{}{"zlib/libpng License"},
+		"hashes":   map[string]interface{}{},
+	},
+}
+
+var cycloneDXDependencies = map[string]interface{}{
+	"app":                        []interface{}{"pkg:generic/openssl@3.0.13"},
+	"pkg:generic/openssl@3.0.13": []interface{}{"pkg:generic/zlib@1.3.1"},
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+
+	tests := []struct {
+		name             string
+		sbom             Sbom
+		wantFormat       string
+		wantSpecVersion  string
+		wantPackages     []interface{}
+		wantDependencies map[string]interface{}
+		wantErr          bool
+	}{
+		{
+			name:            "spdx json",
+			sbom:            Sbom{Path: "app.spdx.json"},
+			wantFormat:      "spdx",
+			wantSpecVersion: "SPDX-2.3",
+			wantPackages: []interface{}{
+				map[string]interface{}{
+					"id":       "SPDXRef-Package-app",
+					"name":     "app",
+					"version":  "1.0.0",
+					"purl":     "",
+					"supplier": "",
+					"licenses": []interface{}{},
+					"hashes":   map[string]interface{}{},
+				},
+				map[string]interface{}{
+					"id":       "SPDXRef-Package-openssl",
+					"name":     "openssl",
+					"version":  "3.0.13",
+					"purl":     "pkg:generic/openssl@3.0.13",
+					"supplier": "OpenSSL",
+					"licenses": []interface{}{"Apache-2.0"},
+					"hashes":   map[string]interface{}{"sha256": "88525753f79d3bec27d2fa7c66aa0b92b3aa9498dafd93d7cfa4b3780cdae313"},
+				},
+				map[string]interface{}{
+					"id":       "SPDXRef-Package-zlib",
+					"name":     "zlib",
+					"version":  "1.3.1",
+					"purl":     "pkg:generic/zlib@1.3.1",
+					"supplier": "",
+					"licenses": []interface{}{"Zlib"},
+					"hashes":   map[string]interface{}{"sha1": "f535367b1a11e2f9ac3bec723fb007fbc0d189e5"},
+				},
+			},
+			wantDependencies: map[string]interface{}{
+				"SPDXRef-Package-app":     []interface{}{"SPDXRef-Package-openssl"},
+				"SPDXRef-Package-openssl": []interface{}{"SPDXRef-Package-zlib"},
+			},
+		},
+		{
+			name:             "cyclonedx json",
+			sbom:             Sbom{Path: "app.cdx.json"},
+			wantFormat:       "cyclonedx",
+			wantSpecVersion:  "1.5",
+			wantPackages:     cycloneDXPackages,
+			wantDependencies: cycloneDXDependencies,
+		},
+		{
+			name:             "cyclonedx xml",
+			sbom:             Sbom{Path: "app.cdx.xml"},
+			wantFormat:       "cyclonedx",
+			wantSpecVersion:  "1.5",
+			wantPackages:     cycloneDXPackages,
+			wantDependencies: cycloneDXDependencies,
+		},
+		{
+			name:             "explicit format",
+			sbom:             Sbom{Path: "app.cdx.json", Format: FormatCycloneDXJSON},
+			wantFormat:       "cyclonedx",
+			wantSpecVersion:  "1.5",
+			wantPackages:     cycloneDXPackages,
+			wantDependencies: cycloneDXDependencies,
+		},
+		{
+			name:    "mismatched format",
+			sbom:    Sbom{Path: "app.cdx.json", Format: FormatSpdxJSON},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			sbom:    Sbom{Path: "unknown.json"},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			sbom:    Sbom{Path: "missing.json"},
+			wantErr: true,
+		},
+		{
+			name:    "checksum mismatch",
+			sbom:    Sbom{Path: "app.cdx.json@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.sbom.Name = "app"
+			domain, err := CreateSbomDomain(&SbomSpec{Sboms: []Sbom{tt.sbom}})
+			require.NoError(t, err)
+
+			resources, err := domain.GetResources(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			result, ok := resources["app"].(map[string]interface{})
+			require.True(t, ok)
+			if tt.wantErr {
+				require.Empty(t, result)
+				return
+			}
+
+			require.Equal(t, tt.wantFormat, result["format"])
+			require.Equal(t, tt.wantSpecVersion, result["spec-version"])
+			require.Equal(t, "app", result["name"])
+			if diff := cmp.Diff(tt.wantPackages, result["packages"]); diff != "" {
+				t.Errorf("wrong packages (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDependencies, result["dependencies"]); diff != "" {
+				t.Errorf("wrong dependencies (-want +got):\n%s", diff)
+			}
+			require.NotEmpty(t, result["document"])
+		})
+	}
+}
+
+func TestOriginalDocument(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	domain, err := CreateSbomDomain(&SbomSpec{Sboms: []Sbom{
+		{Name: "spdx", Path: "app.spdx.json"},
+		{Name: "xml", Path: "app.cdx.xml"},
+	}})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(ctx)
+	require.NoError(t, err)
+
+	spdx := resources["spdx"].(map[string]interface{})["document"].(map[string]interface{})
+	require.Equal(t, "https://example.com/spdx/app-1.0.0", spdx["documentNamespace"])
+
+	// XML documents are returned in their JSON representation
+	xml := resources["xml"].(map[string]interface{})["document"].(map[string]interface{})
+	require.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", xml["serialNumber"])
+	require.Len(t, xml["components"], 2)
+}
+
+func TestCreateSbomDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *SbomSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no sboms", spec: &SbomSpec{}, wantErr: true},
+		{name: "valid", spec: &SbomSpec{Sboms: []Sbom{{Name: "a", Path: "a.json"}, {Name: "b", Path: "b.xml", Format: FormatCycloneDXXML}}}},
+		{name: "missing name", spec: &SbomSpec{Sboms: []Sbom{{Path: "a.json"}}}, wantErr: true},
+		{name: "duplicate name", spec: &SbomSpec{Sboms: []Sbom{{Name: "a", Path: "a.json"}, {Name: "a", Path: "b.json"}}}, wantErr: true},
+		{name: "missing path", spec: &SbomSpec{Sboms: []Sbom{{Name: "a"}}}, wantErr: true},
+		{name: "unsupported format", spec: &SbomSpec{Sboms: []Sbom{{Name: "a", Path: "a.spdx", Format: "spdx-tag-value"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateSbomDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateSbomDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
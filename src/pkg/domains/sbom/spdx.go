@@ -0,0 +1,79 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+// readSpdx parses an SPDX 2.x JSON document, converting older versions to the current model
+func readSpdx(b []byte) (*document, error) {
+	doc, err := spdxjson.Read(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing spdx document: %w", err)
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(b, &original); err != nil {
+		return nil, fmt.Errorf("error parsing spdx document: %w", err)
+	}
+
+	result := &document{
+		format:       normalizedSpdx,
+		specVersion:  doc.SPDXVersion,
+		name:         doc.DocumentName,
+		packages:     make([]pkg, 0, len(doc.Packages)),
+		dependencies: make(map[string][]string),
+		original:     original,
+	}
+	// spdxVersion is the version of the original document, before conversion
+	if v, ok := original["spdxVersion"].(string); ok {
+		result.specVersion = v
+	}
+
+	for _, p := range doc.Packages {
+		if p == nil {
+			continue
+		}
+		normalized := pkg{
+			id:       common.RenderElementID(p.PackageSPDXIdentifier),
+			name:     p.PackageName,
+			version:  p.PackageVersion,
+			licenses: make([]string, 0),
+			hashes:   make(map[string]string, len(p.PackageChecksums)),
+		}
+		if p.PackageSupplier != nil && p.PackageSupplier.Supplier != "NOASSERTION" {
+			normalized.supplier = p.PackageSupplier.Supplier
+		}
+		normalized.licenses = appendLicense(normalized.licenses, p.PackageLicenseDeclared)
+		normalized.licenses = appendLicense(normalized.licenses, p.PackageLicenseConcluded)
+		for _, checksum := range p.PackageChecksums {
+			normalized.hashes[normalizeAlgorithm(string(checksum.Algorithm))] = checksum.Value
+		}
+		for _, ref := range p.PackageExternalReferences {
+			if ref != nil && strings.EqualFold(ref.RefType, "purl") && normalized.purl == "" {
+				normalized.purl = ref.Locator
+			}
+		}
+		result.packages = append(result.packages, normalized)
+	}
+
+	for _, r := range doc.Relationships {
+		if r == nil || r.RefA.SpecialID != "" || r.RefB.SpecialID != "" {
+			continue
+		}
+		a, b := common.RenderDocElementID(r.RefA), common.RenderDocElementID(r.RefB)
+		relationship := strings.ToUpper(r.Relationship)
+		switch {
+		case relationship == "DEPENDS_ON":
+			result.dependencies[a] = append(result.dependencies[a], b)
+		case strings.HasSuffix(relationship, "DEPENDENCY_OF"):
+			// DEPENDENCY_OF and its RUNTIME_, BUILD_, DEV_, OPTIONAL_ and TEST_ variants point the other way
+			result.dependencies[b] = append(result.dependencies[b], a)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Format is the serialization of an SBOM document
+type Format string
+
+const (
+	FormatSpdxJSON      Format = "spdx-json"
+	FormatCycloneDXJSON Format = "cyclonedx-json"
+	FormatCycloneDXXML  Format = "cyclonedx-xml"
+)
+
+// SbomDomain reads SPDX and CycloneDX documents and normalizes them into a common schema
+type SbomDomain struct {
+	Spec *SbomSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// SbomSpec is the user-defined specification of SBOM documents to read
+type SbomSpec struct {
+	Sboms []Sbom `json:"sboms" yaml:"sboms"`
+}
+
+// Sbom is a single SBOM document
+type Sbom struct {
+	// Name is the key of the document in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Path is a local file path or a remote URL, optionally suffixed with @<sha256 checksum>
+	Path string `json:"path" yaml:"path"`
+	// Format of the document, detected from the content if not specified
+	Format Format `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+func CreateSbomDomain(spec *SbomSpec) (types.Domain, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	if len(spec.Sboms) == 0 {
+		return nil, errors.New("some sboms must be specified")
+	}
+
+	var errs error
+	names := make(map[string]bool, len(spec.Sboms))
+	for _, s := range spec.Sboms {
+		if s.Name == "" {
+			errs = errors.Join(errs, errors.New("sbom name cannot be empty"))
+		} else if names[s.Name] {
+			errs = errors.Join(errs, fmt.Errorf("sbom name %s must be unique", s.Name))
+		}
+		names[s.Name] = true
+
+		if s.Path == "" {
+			errs = errors.Join(errs, fmt.Errorf("sbom %s: path cannot be empty", s.Name))
+		}
+		switch s.Format {
+		case "", FormatSpdxJSON, FormatCycloneDXJSON, FormatCycloneDXXML:
+		default:
+			errs = errors.Join(errs, fmt.Errorf("sbom %s: unsupported format %s", s.Name, s.Format))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return SbomDomain{Spec: spec}, nil
+}
+
+// GetResources returns the normalized documents keyed by sbom name
+func (d SbomDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.readSboms(ctx)
+}
+
+// IsExecutable returns false; documents are only read.
+func (d SbomDomain) IsExecutable() bool { return false }
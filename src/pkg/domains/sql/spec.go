@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var defaultTimeout = 30 * time.Second
+
+// validateSpec validates the entire spec and may return multiple errors
+func validateSpec(spec *SqlSpec) (domain SqlDomain, errs error) {
+	if spec == nil {
+		return domain, errors.New("spec is required")
+	}
+
+	switch spec.Driver {
+	case DriverSqlite, DriverPostgres:
+	case "":
+		errs = errors.Join(errs, errors.New("driver is required"))
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unsupported driver %s", spec.Driver))
+	}
+
+	sources := 0
+	for _, s := range []string{spec.Dsn, spec.DsnEnv, spec.DsnFile} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		errs = errors.Join(errs, errors.New("exactly one of dsn, dsn-env, or dsn-file must be specified"))
+	}
+	if hasPassword(spec.Dsn) {
+		errs = errors.Join(errs, errors.New("dsn cannot contain a password, use dsn-env or dsn-file"))
+	}
+
+	if len(spec.Queries) == 0 {
+		errs = errors.Join(errs, errors.New("some queries must be specified"))
+	}
+	names := make(map[string]bool, len(spec.Queries))
+	for _, q := range spec.Queries {
+		if q.Name == "" {
+			errs = errors.Join(errs, errors.New("query name cannot be empty"))
+		} else if names[q.Name] {
+			errs = errors.Join(errs, fmt.Errorf("query name %s must be unique", q.Name))
+		}
+		names[q.Name] = true
+		if strings.TrimSpace(q.Query) == "" {
+			errs = errors.Join(errs, fmt.Errorf("query %s: query cannot be empty", q.Name))
+		} else if err := checkSingleStatement(q.Query); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("query %s: %w", q.Name, err))
+		}
+	}
+
+	domain.timeout = defaultTimeout
+	if spec.Timeout != "" {
+		timeout, err := time.ParseDuration(spec.Timeout)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid timeout: %w", err))
+		} else if timeout <= 0 {
+			errs = errors.Join(errs, fmt.Errorf("timeout must be greater than zero, got %s", spec.Timeout))
+		}
+		domain.timeout = timeout
+	}
+
+	domain.spec = spec
+	return domain, errs
+}
+
+// hasPassword reports whether a URL or key=value data source name includes a password
+func hasPassword(dsn string) bool {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+		if u.Query().Has("password") {
+			return true
+		}
+	}
+	for _, field := range strings.Fields(dsn) {
+		if strings.HasPrefix(strings.ToLower(field), "password=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// database drivers
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// postgresReadOnlyParam is the run-time parameter that makes every transaction of a postgres session read-only
+const postgresReadOnlyParam = "default_transaction_read_only"
+
+func (d SqlDomain) runQueries(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	resources := make(types.DomainResources, len(d.spec.Queries))
+	for _, q := range d.spec.Queries {
+		// Assign empty data value for reporting purposes
+		resources[q.Name] = []interface{}{}
+	}
+
+	dsn, err := d.resolveDsn(workDir)
+	if err != nil {
+		return resources, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	db, err := dbsql.Open(string(d.spec.Driver), dsn)
+	if err != nil {
+		return resources, fmt.Errorf("error opening database: %w", err)
+	}
+	defer db.Close()
+
+	// A single connection is used so that session settings apply to the transaction
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return resources, fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, &dbsql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return resources, fmt.Errorf("error starting read-only transaction: %w", err)
+	}
+	// Nothing is ever committed
+	defer tx.Rollback() //nolint:errcheck
+
+	var errs error
+	for _, q := range d.spec.Queries {
+		rows, err := queryRows(ctx, tx, q)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("query %s: %w", q.Name, err))
+			if d.spec.Driver == DriverPostgres {
+				// postgres aborts the transaction after an error, so the remaining queries cannot run
+				return resources, errs
+			}
+			continue
+		}
+		resources[q.Name] = rows
+	}
+	return resources, errs
+}
+
+// resolveDsn reads the data source name from the spec, environment or file.
+// Relative sqlite database paths are resolved against the working directory.
+func (d SqlDomain) resolveDsn(workDir string) (string, error) {
+	dsn := d.spec.Dsn
+	switch {
+	case d.spec.DsnEnv != "":
+		dsn = os.Getenv(d.spec.DsnEnv)
+		if dsn == "" {
+			return "", fmt.Errorf("environment variable %s is not set", d.spec.DsnEnv)
+		}
+	case d.spec.DsnFile != "":
+		path := d.spec.DsnFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		b, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return "", fmt.Errorf("error reading dsn file: %w", err)
+		}
+		dsn = strings.TrimSpace(string(b))
+	}
+
+	switch d.spec.Driver {
+	case DriverSqlite:
+		dsn = readOnlySqliteDsn(resolveSqlitePath(dsn, workDir))
+	case DriverPostgres:
+		dsn = readOnlyPostgresDsn(dsn)
+	}
+	return dsn, nil
+}
+
+// readOnlySqliteDsn opens the database read-only and refuses writes for the connection. The sqlite driver
+// ignores read-only transaction options, so the connection itself must be read-only.
+func readOnlySqliteDsn(dsn string) string {
+	path, query, _ := strings.Cut(dsn, "?")
+	if !strings.HasPrefix(path, "file:") {
+		// query options are only applied to file: URIs
+		path = "file:" + path
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		params = url.Values{}
+	}
+	params.Set("mode", "ro")
+	params.Add("_pragma", "query_only(1)")
+	return path + "?" + params.Encode()
+}
+
+// readOnlyPostgresDsn sets every transaction of the session read-only, in addition to the read-only
+// transaction the queries are run in
+func readOnlyPostgresDsn(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		params := u.Query()
+		params.Set(postgresReadOnlyParam, "on")
+		u.RawQuery = params.Encode()
+		return u.String()
+	}
+	// in the key=value form the last value of a key is used
+	return strings.TrimSpace(dsn) + " " + postgresReadOnlyParam + "=on"
+}
+
+// resolveSqlitePath joins a relative database path, with or without the file: prefix, to the working directory
+func resolveSqlitePath(dsn, workDir string) string {
+	prefix := ""
+	path := dsn
+	if strings.HasPrefix(dsn, "file:") {
+		prefix = "file:"
+		path = strings.TrimPrefix(dsn, "file:")
+	}
+	if path == "" || strings.HasPrefix(path, ":memory:") || strings.HasPrefix(path, "/") {
+		return dsn
+	}
+	return prefix + filepath.Join(workDir, path)
+}
+
+// queryRows prepares the single statement of the query and returns each row as a map of column name to value
+func queryRows(ctx context.Context, tx *dbsql.Tx, q Query) ([]interface{}, error) {
+	if err := checkSingleStatement(q.Query); err != nil {
+		return nil, err
+	}
+	stmt, err := tx.PrepareContext(ctx, q.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, q.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = normalizeValue(values[i])
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// normalizeValue converts driver values to types that serialize predictably
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,235 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*SqlDomain)(nil)
+
+// createDatabase creates a sqlite database with lockout settings and users
+func createDatabase(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := dbsql.Open("sqlite", filepath.Join(dir, "app.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, stmt := range []string{
+		"CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT)",
+		"INSERT INTO settings VALUES ('lockout_threshold', '5'), ('lockout_duration', '15m')",
+		"CREATE TABLE users (name TEXT, role TEXT, failed_logins INTEGER, mfa BOOLEAN)",
+		"INSERT INTO users VALUES ('alice', 'admin', 0, true), ('bob', 'viewer', 3, false)",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	return dir
+}
+
+func TestGetResources(t *testing.T) {
+	t.Parallel()
+
+	dir := createDatabase(t)
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	domain, err := CreateSqlDomain(&SqlSpec{
+		Driver: DriverSqlite,
+		Dsn:    "app.db",
+		Queries: []Query{
+			{Name: "settings", Query: "SELECT key, value FROM settings ORDER BY key"},
+			{Name: "admins", Query: "SELECT name, failed_logins, mfa FROM users WHERE role = ?", Args: []interface{}{"admin"}},
+			{Name: "none", Query: "SELECT name FROM users WHERE failed_logins > 10"},
+		},
+	})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(ctx)
+	require.NoError(t, err)
+	require.Equal(t, types.DomainResources{
+		"settings": []interface{}{
+			map[string]interface{}{"key": "lockout_duration", "value": "15m"},
+			map[string]interface{}{"key": "lockout_threshold", "value": "5"},
+		},
+		"admins": []interface{}{
+			map[string]interface{}{"name": "alice", "failed_logins": int64(0), "mfa": int64(1)},
+		},
+		"none": []interface{}{},
+	}, resources)
+}
+
+func TestGetResourcesReadOnly(t *testing.T) {
+	t.Parallel()
+
+	dir := createDatabase(t)
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	domain, err := CreateSqlDomain(&SqlSpec{
+		Driver: DriverSqlite,
+		Dsn:    "file:app.db",
+		Queries: []Query{
+			{Name: "delete", Query: "DELETE FROM users RETURNING name"},
+			{Name: "drop", Query: "DROP TABLE settings"},
+			{Name: "invalid", Query: "SELECT * FROM missing"},
+			{Name: "users", Query: "SELECT count(*) AS total FROM users"},
+		},
+	})
+	require.NoError(t, err)
+
+	resources, err := domain.GetResources(ctx)
+	require.Error(t, err)
+	require.Equal(t, []interface{}{}, resources["delete"])
+	require.Equal(t, []interface{}{}, resources["drop"])
+	require.Equal(t, []interface{}{}, resources["invalid"])
+	// the failed statements did not modify the database, and later queries still run
+	require.Equal(t, []interface{}{map[string]interface{}{"total": int64(2)}}, resources["users"])
+}
+
+func TestGetResourcesStackedStatements(t *testing.T) {
+	t.Parallel()
+
+	dir := createDatabase(t)
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	// the domain is created without validation, so that the statements reach the database
+	domain := SqlDomain{
+		spec: &SqlSpec{
+			Driver: DriverSqlite,
+			Dsn:    "app.db",
+			Queries: []Query{
+				{Name: "commit", Query: "COMMIT; PRAGMA query_only = OFF; DELETE FROM users"},
+				{Name: "stacked", Query: "SELECT 1; DELETE FROM users"},
+				{Name: "pragma", Query: "PRAGMA query_only = OFF"},
+				{Name: "delete", Query: "DELETE FROM users RETURNING name"},
+			},
+		},
+		timeout: defaultTimeout,
+	}
+
+	resources, err := domain.GetResources(ctx)
+	require.Error(t, err)
+	require.Equal(t, []interface{}{}, resources["commit"])
+	require.Equal(t, []interface{}{}, resources["stacked"])
+	require.Equal(t, []interface{}{}, resources["delete"])
+
+	// the database is opened read-only, so no statement modified it
+	db, err := dbsql.Open("sqlite", filepath.Join(dir, "app.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	var total int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM users").Scan(&total))
+	require.Equal(t, 2, total)
+}
+
+func TestReadOnlyDsn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		driver Driver
+		dsn    string
+		want   string
+	}{
+		{name: "sqlite path", driver: DriverSqlite, dsn: "/data/app.db", want: "file:/data/app.db?_pragma=query_only%281%29&mode=ro"},
+		{name: "sqlite uri with options", driver: DriverSqlite, dsn: "file:/data/app.db?mode=rwc&_pragma=busy_timeout(100)", want: "file:/data/app.db?_pragma=busy_timeout%28100%29&_pragma=query_only%281%29&mode=ro"},
+		{name: "postgres key value", driver: DriverPostgres, dsn: "host=db user=lula default_transaction_read_only=off", want: "host=db user=lula default_transaction_read_only=off default_transaction_read_only=on"},
+		{name: "postgres url", driver: DriverPostgres, dsn: "postgres://lula@db/app?sslmode=require", want: "postgres://lula@db/app?default_transaction_read_only=on&sslmode=require"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := SqlDomain{spec: &SqlSpec{Driver: tt.driver, Dsn: tt.dsn}}
+			dsn, err := domain.resolveDsn("/work")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, dsn)
+		})
+	}
+}
+
+func TestGetResourcesDsnSources(t *testing.T) {
+	dir := createDatabase(t)
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dsn"), []byte(filepath.Join(dir, "app.db")+"\n"), 0600))
+	t.Setenv("LULA_TEST_SQL_DSN", filepath.Join(dir, "app.db"))
+
+	tests := []struct {
+		name    string
+		spec    SqlSpec
+		wantErr bool
+	}{
+		{name: "dsn file", spec: SqlSpec{DsnFile: "dsn"}},
+		{name: "dsn env", spec: SqlSpec{DsnEnv: "LULA_TEST_SQL_DSN"}},
+		{name: "missing dsn file", spec: SqlSpec{DsnFile: "missing"}, wantErr: true},
+		{name: "unset dsn env", spec: SqlSpec{DsnEnv: "LULA_TEST_SQL_DSN_UNSET"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Driver = DriverSqlite
+			tt.spec.Queries = []Query{{Name: "users", Query: "SELECT name FROM users ORDER BY name"}}
+			domain, err := CreateSqlDomain(&tt.spec)
+			require.NoError(t, err)
+
+			resources, err := domain.GetResources(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				require.Equal(t, []interface{}{}, resources["users"])
+				return
+			}
+			require.Len(t, resources["users"], 2)
+		})
+	}
+}
+
+func TestCreateSqlDomain(t *testing.T) {
+	t.Parallel()
+
+	queries := []Query{{Name: "q", Query: "SELECT 1"}}
+	tests := []struct {
+		name    string
+		spec    *SqlSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "valid sqlite", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: queries}},
+		{name: "valid postgres", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "host=db user=lula dbname=app sslmode=require", Queries: queries, Timeout: "5s"}},
+		{name: "valid postgres url", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "postgres://lula@db/app", Queries: queries}},
+		{name: "missing driver", spec: &SqlSpec{Dsn: "app.db", Queries: queries}, wantErr: true},
+		{name: "unsupported driver", spec: &SqlSpec{Driver: "mysql", Dsn: "app", Queries: queries}, wantErr: true},
+		{name: "no dsn", spec: &SqlSpec{Driver: DriverSqlite, Queries: queries}, wantErr: true},
+		{name: "multiple dsn sources", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", DsnEnv: "DSN", Queries: queries}, wantErr: true},
+		{name: "password in key value dsn", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "host=db user=lula password=secret", Queries: queries}, wantErr: true},
+		{name: "password in url dsn", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "postgres://lula:secret@db/app", Queries: queries}, wantErr: true},
+		{name: "password in url query", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "postgres://db/app?user=lula&password=secret", Queries: queries}, wantErr: true},
+		{name: "no queries", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db"}, wantErr: true},
+		{name: "duplicate query name", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: append(queries, queries...)}, wantErr: true},
+		{name: "empty query", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: []Query{{Name: "q"}}}, wantErr: true},
+		{name: "invalid timeout", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: queries, Timeout: "soon"}, wantErr: true},
+		{name: "zero timeout", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: queries, Timeout: "0s"}, wantErr: true},
+		{name: "negative timeout", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: queries, Timeout: "-1s"}, wantErr: true},
+		{name: "single statement with trailing semicolon and comment", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: []Query{{Name: "q", Query: "SELECT ';' AS x; -- done"}}}},
+		{name: "semicolons in quotes and comments", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "postgres://lula@db/app", Queries: []Query{{Name: "q", Query: `SELECT "a;b", $body$;$body$ /* ; */ FROM t`}}}},
+		{name: "stacked statements", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: []Query{{Name: "q", Query: "SELECT 1; DELETE FROM users"}}}, wantErr: true},
+		{name: "stacked commit", spec: &SqlSpec{Driver: DriverSqlite, Dsn: "app.db", Queries: []Query{{Name: "q", Query: "COMMIT; PRAGMA query_only = OFF; DELETE FROM users"}}}, wantErr: true},
+		{name: "stacked after comment", spec: &SqlSpec{Driver: DriverPostgres, Dsn: "postgres://lula@db/app", Queries: []Query{{Name: "q", Query: "SELECT 1 /* ; */; -- x\nSET default_transaction_read_only = off"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateSqlDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateSqlDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
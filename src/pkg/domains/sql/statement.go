@@ -0,0 +1,61 @@
+package sql
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// dollarQuotePattern matches the opening tag of a postgres dollar-quoted string, e.g. $$ or $body$
+var dollarQuotePattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// errMultipleStatements is returned for a query with a statement after its first one
+var errMultipleStatements = errors.New("query must be a single statement")
+
+// checkSingleStatement returns an error if the query contains more than one statement. Quoted strings and
+// identifiers, comments, and postgres dollar-quoted strings are skipped, so semicolons within them are
+// allowed, as are trailing semicolons and comments.
+func checkSingleStatement(query string) error {
+	ended := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return nil
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil
+			}
+			i += end + 3
+		case c == ';':
+			ended = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case ended:
+			return errMultipleStatements
+		case c == '\'' || c == '"' || c == '`':
+			i = skipPast(query, i+1, string(c))
+		case c == '[':
+			i = skipPast(query, i+1, "]")
+		case c == '$':
+			if tag := dollarQuotePattern.FindString(query[i:]); tag != "" {
+				i = skipPast(query, i+len(tag), tag)
+			}
+		}
+	}
+	return nil
+}
+
+// skipPast returns the index of the last byte of the first occurrence of the delimiter at or after start,
+// or the end of the query if there is none
+func skipPast(query string, start int, delimiter string) int {
+	end := strings.Index(query[start:], delimiter)
+	if end < 0 {
+		return len(query)
+	}
+	return start + end + len(delimiter) - 1
+}
@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Driver is a supported database driver
+type Driver string
+
+const (
+	DriverSqlite   Driver = "sqlite"
+	DriverPostgres Driver = "postgres"
+)
+
+// SqlDomain runs read-only queries against a database
+type SqlDomain struct {
+	spec    *SqlSpec
+	timeout time.Duration
+}
+
+// SqlSpec is the user-defined specification of the database and queries.
+// Exactly one of Dsn, DsnEnv or DsnFile must be specified.
+type SqlSpec struct {
+	// Driver is the database driver: sqlite or postgres
+	Driver Driver `json:"driver" yaml:"driver"`
+	// Dsn is the data source name, which cannot contain a password; use DsnEnv, DsnFile or the
+	// driver's own credential sources (e.g. PGPASSWORD or ~/.pgpass) for credentials
+	Dsn string `json:"dsn,omitempty" yaml:"dsn,omitempty"`
+	// DsnEnv is the name of an environment variable containing the data source name
+	DsnEnv string `json:"dsn-env,omitempty" yaml:"dsn-env,omitempty"`
+	// DsnFile is the path of a file containing the data source name
+	DsnFile string `json:"dsn-file,omitempty" yaml:"dsn-file,omitempty"`
+	// Queries are run in a single read-only transaction
+	Queries []Query `json:"queries" yaml:"queries"`
+	// Timeout for connecting and running all queries, defaults to 30s
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Query is a single named query
+type Query struct {
+	// Name is the key of the rows in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Query is the SQL statement
+	Query string `json:"query" yaml:"query"`
+	// Args are the values of the statement placeholders, e.g. $1 for postgres or ? for sqlite
+	Args []interface{} `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+func CreateSqlDomain(spec *SqlSpec) (types.Domain, error) {
+	return validateSpec(spec)
+}
+
+// GetResources returns the rows of each query keyed by query name
+func (d SqlDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.runQueries(ctx)
+}
+
+// IsExecutable returns false; queries are run in read-only transactions.
+func (d SqlDomain) IsExecutable() bool { return false }
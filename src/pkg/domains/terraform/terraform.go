@@ -0,0 +1,292 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// Values of the normalized "kind" field
+const (
+	kindPlan  = "plan"
+	kindState = "state"
+)
+
+// document holds the fields of the `terraform show -json` output for plans and states,
+// and of terraform.tfstate files (format version 4), that are normalized
+type document struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	PlannedValues    *values          `json:"planned_values"`
+	ResourceChanges  []resourceChange `json:"resource_changes"`
+	Values           *values          `json:"values"`
+
+	// Version, Lineage and Resources are only present in terraform.tfstate files
+	Version   *int            `json:"version"`
+	Lineage   string          `json:"lineage"`
+	Resources []stateResource `json:"resources"`
+}
+
+type values struct {
+	RootModule module `json:"root_module"`
+}
+
+type module struct {
+	Address      string          `json:"address"`
+	Resources    []valueResource `json:"resources"`
+	ChildModules []module        `json:"child_modules"`
+}
+
+type valueResource struct {
+	Address      string                 `json:"address"`
+	Mode         string                 `json:"mode"`
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	Index        interface{}            `json:"index"`
+	ProviderName string                 `json:"provider_name"`
+	Values       map[string]interface{} `json:"values"`
+}
+
+type resourceChange struct {
+	Address       string      `json:"address"`
+	ModuleAddress string      `json:"module_address"`
+	Mode          string      `json:"mode"`
+	Type          string      `json:"type"`
+	Name          string      `json:"name"`
+	Index         interface{} `json:"index"`
+	ProviderName  string      `json:"provider_name"`
+	Change        struct {
+		Actions      []string    `json:"actions"`
+		Before       interface{} `json:"before"`
+		After        interface{} `json:"after"`
+		AfterUnknown interface{} `json:"after_unknown"`
+	} `json:"change"`
+}
+
+type stateResource struct {
+	Module    string `json:"module"`
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	Instances []struct {
+		IndexKey   interface{}            `json:"index_key"`
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"instances"`
+}
+
+func (d TerraformDomain) readFiles(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	resources := make(types.DomainResources, len(d.Spec.Files))
+	var errs error
+	for _, f := range d.Spec.Files {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := d.readFile(f, workDir)
+		if err != nil {
+			// Assign empty data value for reporting purposes
+			resources[f.Name] = map[string]interface{}{}
+			errs = errors.Join(errs, fmt.Errorf("file %s: %w", f.Name, err))
+			continue
+		}
+		resources[f.Name] = result
+	}
+	return resources, errs
+}
+
+func (d TerraformDomain) readFile(f TerraformFile, workDir string) (map[string]interface{}, error) {
+	b, err := network.Fetch(f.Path, network.WithBaseDir(workDir))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", f.Path, err)
+	}
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", f.Path, err)
+	}
+
+	kind := kindState
+	var resources []map[string]interface{}
+	changes := make(map[string]interface{})
+	switch {
+	case doc.PlannedValues != nil || doc.ResourceChanges != nil:
+		kind = kindPlan
+		if doc.PlannedValues != nil {
+			resources = flattenModule(doc.PlannedValues.RootModule)
+		}
+		for _, rc := range doc.ResourceChanges {
+			if d.matches(rc.Type, rc.ModuleAddress) {
+				changes[rc.Address] = normalizeChange(rc)
+			}
+		}
+	case doc.Values != nil:
+		resources = flattenModule(doc.Values.RootModule)
+	case doc.Version != nil:
+		resources = flattenState(doc.Resources)
+	case doc.FormatVersion != "":
+		// `terraform show -json` omits the values of an empty state
+	default:
+		return nil, errors.New("document is not a terraform plan or state")
+	}
+
+	byAddress := make(map[string]interface{}, len(resources))
+	byType := make(map[string]interface{})
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i]["address"].(string) < resources[j]["address"].(string)
+	})
+	for _, r := range resources {
+		resourceType, module := r["type"].(string), r["module"].(string)
+		if !d.matches(resourceType, module) {
+			continue
+		}
+		byAddress[r["address"].(string)] = r
+		list, _ := byType[resourceType].([]interface{})
+		byType[resourceType] = append(list, r)
+	}
+
+	return map[string]interface{}{
+		"kind":              kind,
+		"terraform-version": doc.TerraformVersion,
+		"resources":         byAddress,
+		"resources-by-type": byType,
+		"changes":           changes,
+	}, nil
+}
+
+// matches reports whether a resource is selected by the type and module filters
+func (d TerraformDomain) matches(resourceType, module string) bool {
+	if len(d.Spec.ResourceTypes) > 0 && !slices.Contains(d.Spec.ResourceTypes, resourceType) {
+		return false
+	}
+	if len(d.Spec.Modules) == 0 {
+		return true
+	}
+	for _, m := range d.Spec.Modules {
+		if m == RootModule {
+			if module == "" {
+				return true
+			}
+			continue
+		}
+		// child modules and module instances, e.g. module.network.module.subnets or module.network[0]
+		if module == m || strings.HasPrefix(module, m+".") || strings.HasPrefix(module, m+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenModule returns the resources of the module and all of its child modules
+func flattenModule(m module) []map[string]interface{} {
+	resources := make([]map[string]interface{}, 0, len(m.Resources))
+	for _, r := range m.Resources {
+		resources = append(resources, normalizeResource(r.Address, m.Address, r.Mode, r.Type, r.Name, r.Index, r.ProviderName, r.Values))
+	}
+	for _, child := range m.ChildModules {
+		resources = append(resources, flattenModule(child)...)
+	}
+	return resources
+}
+
+// flattenState returns a resource for each instance in a terraform.tfstate file
+func flattenState(stateResources []stateResource) []map[string]interface{} {
+	resources := make([]map[string]interface{}, 0, len(stateResources))
+	for _, r := range stateResources {
+		provider := normalizeProvider(r.Provider)
+		for _, instance := range r.Instances {
+			address := stateAddress(r, instance.IndexKey)
+			resources = append(resources, normalizeResource(address, r.Module, r.Mode, r.Type, r.Name, instance.IndexKey, provider, instance.Attributes))
+		}
+	}
+	return resources
+}
+
+func normalizeResource(address, module, mode, resourceType, name string, index interface{}, provider string, values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"address":  address,
+		"module":   module,
+		"mode":     mode,
+		"type":     resourceType,
+		"name":     name,
+		"index":    index,
+		"provider": provider,
+		"values":   values,
+	}
+}
+
+func normalizeChange(rc resourceChange) map[string]interface{} {
+	actions := make([]interface{}, 0, len(rc.Change.Actions))
+	for _, a := range rc.Change.Actions {
+		actions = append(actions, a)
+	}
+	return map[string]interface{}{
+		"address":       rc.Address,
+		"module":        rc.ModuleAddress,
+		"mode":          rc.Mode,
+		"type":          rc.Type,
+		"name":          rc.Name,
+		"index":         rc.Index,
+		"provider":      rc.ProviderName,
+		"action":        changeAction(rc.Change.Actions),
+		"actions":       actions,
+		"before":        rc.Change.Before,
+		"after":         rc.Change.After,
+		"after-unknown": rc.Change.AfterUnknown,
+	}
+}
+
+// changeAction summarizes the planned actions, where a delete and create is a replace
+func changeAction(actions []string) string {
+	if len(actions) == 2 && slices.Contains(actions, "create") && slices.Contains(actions, "delete") {
+		return "replace"
+	}
+	return strings.Join(actions, "-")
+}
+
+// stateAddress builds the resource address used by `terraform show -json` for a state instance
+func stateAddress(r stateResource, indexKey interface{}) string {
+	var address strings.Builder
+	if r.Module != "" {
+		address.WriteString(r.Module + ".")
+	}
+	if r.Mode == "data" {
+		address.WriteString("data.")
+	}
+	address.WriteString(r.Type + "." + r.Name)
+	switch key := indexKey.(type) {
+	case string:
+		address.WriteString(fmt.Sprintf("[%q]", key))
+	case float64:
+		address.WriteString(fmt.Sprintf("[%d]", int(key)))
+	}
+	return address.String()
+}
+
+// normalizeProvider converts the state provider configuration, e.g.
+// provider["registry.terraform.io/hashicorp/aws"].west, to the provider name
+func normalizeProvider(provider string) string {
+	start := strings.Index(provider, `["`)
+	end := strings.Index(provider, `"]`)
+	if start == -1 || end < start {
+		return provider
+	}
+	return provider[start+2 : end]
+}
@@ -0,0 +1,201 @@
+package terraform
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*TerraformDomain)(nil)
+
+func getResources(t *testing.T, spec *TerraformSpec) map[string]interface{} {
+	t.Helper()
+
+	domain, err := CreateTerraformDomain(spec)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	resources, err := domain.GetResources(ctx)
+	require.NoError(t, err)
+	result, ok := resources[spec.Files[0].Name].(map[string]interface{})
+	require.True(t, ok)
+	return result
+}
+
+func sortedKeys(m interface{}) []string {
+	return slices.Sorted(maps.Keys(m.(map[string]interface{})))
+}
+
+func TestGetResourcesPlan(t *testing.T) {
+	t.Parallel()
+
+	result := getResources(t, &TerraformSpec{Files: []TerraformFile{{Name: "plan", Path: "plan.json"}}})
+
+	require.Equal(t, "plan", result["kind"])
+	require.Equal(t, "1.9.5", result["terraform-version"])
+	require.Equal(t, []string{
+		"aws_s3_bucket.logs",
+		"aws_s3_bucket_public_access_block.logs",
+		"module.network.aws_vpc.main",
+		"module.network.module.subnets.aws_subnet.private[0]",
+	}, sortedKeys(result["resources"]))
+
+	subnet := result["resources"].(map[string]interface{})["module.network.module.subnets.aws_subnet.private[0]"]
+	if diff := cmp.Diff(map[string]interface{}{
+		"address":  "module.network.module.subnets.aws_subnet.private[0]",
+		"module":   "module.network.module.subnets",
+		"mode":     "managed",
+		"type":     "aws_subnet",
+		"name":     "private",
+		"index":    float64(0),
+		"provider": "registry.terraform.io/hashicorp/aws",
+		"values": map[string]interface{}{
+			"cidr_block":              "10.1.1.0/24",
+			"map_public_ip_on_launch": false,
+		},
+	}, subnet); diff != "" {
+		t.Errorf("wrong resource (-want +got):\n%s", diff)
+	}
+
+	byType := result["resources-by-type"].(map[string]interface{})
+	require.Equal(t, []string{"aws_s3_bucket", "aws_s3_bucket_public_access_block", "aws_subnet", "aws_vpc"}, sortedKeys(byType))
+	require.Len(t, byType["aws_s3_bucket"], 1)
+
+	changes := result["changes"].(map[string]interface{})
+	actions := make(map[string]interface{}, len(changes))
+	for address, change := range changes {
+		actions[address] = change.(map[string]interface{})["action"]
+	}
+	require.Equal(t, map[string]interface{}{
+		"aws_instance.legacy":                                 "delete",
+		"aws_s3_bucket.logs":                                  "create",
+		"aws_s3_bucket_public_access_block.logs":              "update",
+		"module.network.aws_vpc.main":                         "replace",
+		"module.network.module.subnets.aws_subnet.private[0]": "no-op",
+	}, actions)
+
+	update := changes["aws_s3_bucket_public_access_block.logs"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"block_public_acls": false, "block_public_policy": true}, update["before"])
+	require.Equal(t, map[string]interface{}{"block_public_acls": true, "block_public_policy": true}, update["after"])
+	require.Equal(t, []interface{}{"update"}, update["actions"])
+}
+
+func TestGetResourcesState(t *testing.T) {
+	t.Parallel()
+
+	show := getResources(t, &TerraformSpec{Files: []TerraformFile{{Name: "state", Path: "state.json"}}})
+	raw := getResources(t, &TerraformSpec{Files: []TerraformFile{{Name: "state", Path: "terraform.tfstate"}}})
+
+	require.Equal(t, "state", show["kind"])
+	require.Equal(t, map[string]interface{}{}, show["changes"])
+	require.Equal(t, []string{
+		"aws_s3_bucket.logs",
+		"data.aws_caller_identity.current",
+		`module.network.aws_subnet.private["a"]`,
+	}, sortedKeys(show["resources"]))
+
+	// terraform.tfstate files are normalized to the same resources as `terraform show -json`
+	if diff := cmp.Diff(show, raw); diff != "" {
+		t.Errorf("state file differs from show output (-show +raw):\n%s", diff)
+	}
+}
+
+func TestGetResourcesFilters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		resourceTypes []string
+		modules       []string
+		wantResources []string
+		wantChanges   []string
+	}{
+		{
+			name:          "resource types",
+			resourceTypes: []string{"aws_s3_bucket", "aws_instance"},
+			wantResources: []string{"aws_s3_bucket.logs"},
+			wantChanges:   []string{"aws_instance.legacy", "aws_s3_bucket.logs"},
+		},
+		{
+			name:          "root module",
+			modules:       []string{RootModule},
+			wantResources: []string{"aws_s3_bucket.logs", "aws_s3_bucket_public_access_block.logs"},
+			wantChanges:   []string{"aws_instance.legacy", "aws_s3_bucket.logs", "aws_s3_bucket_public_access_block.logs"},
+		},
+		{
+			name:          "module includes child modules",
+			modules:       []string{"module.network"},
+			wantResources: []string{"module.network.aws_vpc.main", "module.network.module.subnets.aws_subnet.private[0]"},
+			wantChanges:   []string{"module.network.aws_vpc.main", "module.network.module.subnets.aws_subnet.private[0]"},
+		},
+		{
+			name:          "type and module",
+			resourceTypes: []string{"aws_subnet"},
+			modules:       []string{"module.network.module.subnets"},
+			wantResources: []string{"module.network.module.subnets.aws_subnet.private[0]"},
+			wantChanges:   []string{"module.network.module.subnets.aws_subnet.private[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getResources(t, &TerraformSpec{
+				Files:         []TerraformFile{{Name: "plan", Path: "plan.json"}},
+				ResourceTypes: tt.resourceTypes,
+				Modules:       tt.modules,
+			})
+			require.Equal(t, tt.wantResources, sortedKeys(result["resources"]))
+			require.Equal(t, tt.wantChanges, sortedKeys(result["changes"]))
+		})
+	}
+}
+
+func TestGetResourcesErrors(t *testing.T) {
+	t.Parallel()
+
+	domain, err := CreateTerraformDomain(&TerraformSpec{Files: []TerraformFile{
+		{Name: "unknown", Path: "unknown.json"},
+		{Name: "missing", Path: "missing.json"},
+		{Name: "plan", Path: "plan.json"},
+	}})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	resources, err := domain.GetResources(ctx)
+	require.Error(t, err)
+	require.Equal(t, map[string]interface{}{}, resources["unknown"])
+	require.Equal(t, map[string]interface{}{}, resources["missing"])
+	require.NotEmpty(t, resources["plan"])
+}
+
+func TestCreateTerraformDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *TerraformSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no files", spec: &TerraformSpec{}, wantErr: true},
+		{name: "valid", spec: &TerraformSpec{Files: []TerraformFile{{Name: "plan", Path: "plan.json"}}, Modules: []string{RootModule, "module.network"}}},
+		{name: "missing name", spec: &TerraformSpec{Files: []TerraformFile{{Path: "plan.json"}}}, wantErr: true},
+		{name: "duplicate name", spec: &TerraformSpec{Files: []TerraformFile{{Name: "a", Path: "a.json"}, {Name: "a", Path: "b.json"}}}, wantErr: true},
+		{name: "missing path", spec: &TerraformSpec{Files: []TerraformFile{{Name: "a"}}}, wantErr: true},
+		{name: "empty module", spec: &TerraformSpec{Files: []TerraformFile{{Name: "a", Path: "a.json"}}, Modules: []string{""}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateTerraformDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTerraformDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// RootModule selects resources in the root module when filtering by module
+const RootModule = "root"
+
+// TerraformDomain reads Terraform plans and states and normalizes their resources and changes
+type TerraformDomain struct {
+	Spec *TerraformSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// TerraformSpec is the user-defined specification of plans and states to read
+type TerraformSpec struct {
+	// Files are plan or state documents, from `terraform show -json` or a terraform.tfstate file
+	Files []TerraformFile `json:"files" yaml:"files"`
+	// ResourceTypes limits the resources and changes to these types, e.g. aws_s3_bucket
+	ResourceTypes []string `json:"resource-types,omitempty" yaml:"resource-types,omitempty"`
+	// Modules limits the resources and changes to these module paths and their child modules,
+	// e.g. module.network. The root module alone is selected with "root".
+	Modules []string `json:"modules,omitempty" yaml:"modules,omitempty"`
+}
+
+// TerraformFile is a single plan or state document
+type TerraformFile struct {
+	// Name is the key of the document in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Path is a local file path or a remote URL, optionally suffixed with @<sha256 checksum>
+	Path string `json:"path" yaml:"path"`
+}
+
+func CreateTerraformDomain(spec *TerraformSpec) (types.Domain, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	if len(spec.Files) == 0 {
+		return nil, errors.New("some files must be specified")
+	}
+
+	var errs error
+	names := make(map[string]bool, len(spec.Files))
+	for _, f := range spec.Files {
+		if f.Name == "" {
+			errs = errors.Join(errs, errors.New("file name cannot be empty"))
+		} else if names[f.Name] {
+			errs = errors.Join(errs, fmt.Errorf("file name %s must be unique", f.Name))
+		}
+		names[f.Name] = true
+
+		if f.Path == "" {
+			errs = errors.Join(errs, fmt.Errorf("file %s: path cannot be empty", f.Name))
+		}
+	}
+	for _, m := range spec.Modules {
+		if m == "" {
+			errs = errors.Join(errs, fmt.Errorf("module cannot be empty, use %q for the root module", RootModule))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return TerraformDomain{Spec: spec}, nil
+}
+
+// GetResources returns the normalized documents keyed by file name
+func (d TerraformDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.readFiles(ctx)
+}
+
+// IsExecutable returns false; documents are only read.
+func (d TerraformDomain) IsExecutable() bool { return false }
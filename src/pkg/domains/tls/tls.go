@@ -0,0 +1,343 @@
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// maxConcurrentHandshakes limits the cipher suites probed at once against a target
+const maxConcurrentHandshakes = 8
+
+// targetTimeoutFactor bounds the inspection of an address, including every handshake, to this many
+// times the connection timeout
+const targetTimeoutFactor = 4
+
+// protocolVersions are probed individually, from oldest to newest
+var protocolVersions = []uint16{
+	cryptotls.VersionTLS10,
+	cryptotls.VersionTLS11,
+	cryptotls.VersionTLS12,
+	cryptotls.VersionTLS13,
+}
+
+// allCipherSuites are every suite Go implements, including insecure ones, so that
+// endpoints accepting weak suites are detected
+var allCipherSuites = append(cryptotls.CipherSuites(), cryptotls.InsecureCipherSuites()...)
+
+// cipherSuites offers every suite Go implements when probing a protocol version
+var cipherSuites = func() []uint16 {
+	ids := make([]uint16, 0, len(allCipherSuites))
+	for _, suite := range allCipherSuites {
+		ids = append(ids, suite.ID)
+	}
+	return ids
+}()
+
+func (d TlsDomain) inspectTargets(ctx context.Context) (types.DomainResources, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok {
+		// if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	timeout := defaultTimeout
+	if d.Spec.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(d.Spec.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	resources := make(types.DomainResources, len(d.Spec.Targets))
+	var errs error
+	for _, target := range d.Spec.Targets {
+		select {
+		case <-ctx.Done():
+			return resources, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		result, err := d.inspectTarget(ctx, target, workDir, timeout)
+		if err != nil {
+			// Assign empty data value for reporting purposes
+			resources[target.Name] = map[string]interface{}{}
+			errs = errors.Join(errs, fmt.Errorf("target %s: %w", target.Name, err))
+			continue
+		}
+		resources[target.Name] = result
+	}
+	return resources, errs
+}
+
+func (d TlsDomain) inspectTarget(ctx context.Context, target Target, workDir string, timeout time.Duration) (map[string]interface{}, error) {
+	bundle := d.Spec.CaBundle
+	if target.CaBundle != "" {
+		bundle = target.CaBundle
+	}
+	var roots *x509.CertPool
+	if bundle != "" {
+		certs, err := readCertificates(bundle, workDir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ca bundle: %w", err)
+		}
+		roots = x509.NewCertPool()
+		for _, cert := range certs {
+			roots.AddCert(cert)
+		}
+	}
+
+	serverName := target.ServerName
+	versions := make([]interface{}, 0)
+	suites := make(map[string]interface{})
+	var chain []*x509.Certificate
+
+	if target.Address != "" {
+		if serverName == "" {
+			host, _, err := net.SplitHostPort(target.Address)
+			if err != nil {
+				return nil, err
+			}
+			serverName = host
+		}
+
+		targetTimeout := targetTimeoutFactor * timeout
+		ctx, cancel := context.WithTimeout(ctx, targetTimeout)
+		defer cancel()
+
+		var lastErr error
+		for _, version := range protocolVersions {
+			state, err := handshake(ctx, target.Address, serverName, version, timeout)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			name := cryptotls.VersionName(version)
+			versions = append(versions, name)
+			suites[name] = supportedSuites(ctx, target.Address, serverName, version, state.CipherSuite, timeout)
+			// the chain presented with the newest protocol version is reported
+			chain = state.PeerCertificates
+		}
+		// a partial inspection would under-report the versions and suites the endpoint accepts
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("inspection of %s did not complete within %s", target.Address, targetTimeout)
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("unable to complete a TLS handshake with %s: %w", target.Address, lastErr)
+		}
+	} else {
+		var err error
+		chain, err = readCertificates(target.File, workDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	certificates := make([]interface{}, 0, len(chain))
+	for _, cert := range chain {
+		certificates = append(certificates, describeCertificate(cert, now))
+	}
+
+	return map[string]interface{}{
+		"server-name":   serverName,
+		"versions":      versions,
+		"cipher-suites": suites,
+		"certificates":  certificates,
+		"verification":  verifyChain(chain, roots, serverName, now),
+	}, nil
+}
+
+// supportedSuites returns the names of the cipher suites the endpoint accepts with a protocol version.
+// Up to TLS 1.2 each suite of the version is offered on its own, with up to maxConcurrentHandshakes
+// handshakes at once; the TLS 1.3 suites cannot be configured, so only the negotiated suite is
+// returned for TLS 1.3.
+func supportedSuites(ctx context.Context, address, serverName string, version, negotiated uint16, timeout time.Duration) []interface{} {
+	if version == cryptotls.VersionTLS13 {
+		return []interface{}{cryptotls.CipherSuiteName(negotiated)}
+	}
+
+	candidates := make([]*cryptotls.CipherSuite, 0)
+	for _, suite := range allCipherSuites {
+		if slices.Contains(suite.SupportedVersions, version) {
+			candidates = append(candidates, suite)
+		}
+	}
+
+	accepted := make([]bool, len(candidates))
+	sem := make(chan struct{}, maxConcurrentHandshakes)
+	var wg sync.WaitGroup
+	for i, suite := range candidates {
+		if suite.ID == negotiated {
+			accepted[i] = true
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, err := handshake(ctx, address, serverName, version, timeout, suite.ID)
+			accepted[i] = err == nil
+		}()
+	}
+	wg.Wait()
+
+	names := make([]interface{}, 0)
+	for i, suite := range candidates {
+		if accepted[i] {
+			names = append(names, suite.Name)
+		}
+	}
+	return names
+}
+
+// handshake connects with a single protocol version, offering the given cipher suites, or every suite if
+// none are given. The chain is not verified during the handshake so that invalid certificates can be reported.
+func handshake(ctx context.Context, address, serverName string, version uint16, timeout time.Duration, suites ...uint16) (cryptotls.ConnectionState, error) {
+	if len(suites) == 0 {
+		suites = cipherSuites
+	}
+	dialer := &cryptotls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		Config: &cryptotls.Config{
+			ServerName:         serverName,
+			MinVersion:         version,
+			MaxVersion:         version,
+			CipherSuites:       suites,
+			InsecureSkipVerify: true, //nolint:gosec // the chain is verified separately and reported as evidence
+		},
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(dialCtx, "tcp", address)
+	if err != nil {
+		return cryptotls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	return conn.(*cryptotls.Conn).ConnectionState(), nil
+}
+
+// verifyChain verifies the leaf against the roots, using the rest of the chain as intermediates.
+// Nil roots use the system trust store.
+func verifyChain(chain []*x509.Certificate, roots *x509.CertPool, serverName string, now time.Time) map[string]interface{} {
+	if len(chain) == 0 {
+		return map[string]interface{}{"verified": false, "error": "no certificates presented"}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return map[string]interface{}{"verified": false, "error": err.Error()}
+	}
+	return map[string]interface{}{"verified": true, "error": ""}
+}
+
+func describeCertificate(cert *x509.Certificate, now time.Time) map[string]interface{} {
+	keyType, keySize := publicKeyInfo(cert)
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	ipAddresses := make([]interface{}, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	uris := make([]interface{}, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return map[string]interface{}{
+		"subject":             cert.Subject.String(),
+		"common-name":         cert.Subject.CommonName,
+		"issuer":              cert.Issuer.String(),
+		"serial-number":       cert.SerialNumber.Text(16),
+		"dns-names":           toInterfaces(cert.DNSNames),
+		"ip-addresses":        ipAddresses,
+		"email-addresses":     toInterfaces(cert.EmailAddresses),
+		"uris":                uris,
+		"not-before":          cert.NotBefore.UTC().Format(time.RFC3339),
+		"not-after":           cert.NotAfter.UTC().Format(time.RFC3339),
+		"days-until-expiry":   int(cert.NotAfter.Sub(now).Hours() / 24),
+		"expired":             now.After(cert.NotAfter),
+		"key-type":            keyType,
+		"key-size":            keySize,
+		"signature-algorithm": cert.SignatureAlgorithm.String(),
+		"is-ca":               cert.IsCA,
+		"sha256-fingerprint":  hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// publicKeyInfo returns the key algorithm and its size in bits
+func publicKeyInfo(cert *x509.Certificate) (string, int) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", ed25519.PublicKeySize * 8
+	default:
+		return cert.PublicKeyAlgorithm.String(), 0
+	}
+}
+
+// readCertificates returns the certificates in a PEM file
+func readCertificates(path, workDir string) ([]*x509.Certificate, error) {
+	b, err := network.Fetch(path, network.WithBaseDir(workDir))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	certs := make([]*x509.Certificate, 0)
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate in %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return certs, nil
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		result = append(result, v)
+	}
+	return result
+}
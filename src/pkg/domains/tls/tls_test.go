@@ -0,0 +1,252 @@
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.Domain = (*TlsDomain)(nil)
+
+// startServer starts a TLS test server and writes its certificate to a trust bundle
+func startServer(t *testing.T, config *cryptotls.Config) (*httptest.Server, string) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, bundle, server.Certificate().Raw)
+	return server, bundle
+}
+
+func writePEM(t *testing.T, path string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}
+
+func getTarget(t *testing.T, spec *TlsSpec) (map[string]interface{}, error) {
+	t.Helper()
+
+	domain, err := CreateTlsDomain(spec)
+	require.NoError(t, err)
+	resources, err := domain.GetResources(context.Background())
+	result, ok := resources[spec.Targets[0].Name].(map[string]interface{})
+	require.True(t, ok)
+	return result, err
+}
+
+func TestGetResourcesEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server, bundle := startServer(t, &cryptotls.Config{})
+	address := server.Listener.Addr().String()
+
+	t.Run("trusted", func(t *testing.T) {
+		result, err := getTarget(t, &TlsSpec{CaBundle: bundle, Targets: []Target{{Name: "server", Address: address}}})
+		require.NoError(t, err)
+
+		require.Equal(t, "127.0.0.1", result["server-name"])
+		require.Contains(t, result["versions"], "TLS 1.2")
+		require.Contains(t, result["versions"], "TLS 1.3")
+		require.NotContains(t, result["versions"], "TLS 1.0")
+		require.Contains(t, result["cipher-suites"], "TLS 1.3")
+		require.Equal(t, map[string]interface{}{"verified": true, "error": ""}, result["verification"])
+
+		certificates := result["certificates"].([]interface{})
+		require.Len(t, certificates, 1)
+		leaf := certificates[0].(map[string]interface{})
+		require.Contains(t, leaf["dns-names"], "example.com")
+		require.Contains(t, leaf["ip-addresses"], "127.0.0.1")
+		require.Equal(t, false, leaf["expired"])
+		require.NotEmpty(t, leaf["key-type"])
+	})
+
+	t.Run("server name mismatch", func(t *testing.T) {
+		result, err := getTarget(t, &TlsSpec{CaBundle: bundle, Targets: []Target{{Name: "server", Address: address, ServerName: "lula.dev"}}})
+		require.NoError(t, err)
+		verification := result["verification"].(map[string]interface{})
+		require.Equal(t, false, verification["verified"])
+		require.NotEmpty(t, verification["error"])
+	})
+
+	t.Run("target bundle overrides spec bundle", func(t *testing.T) {
+		_, otherBundle := startServer(t, &cryptotls.Config{})
+		result, err := getTarget(t, &TlsSpec{CaBundle: otherBundle, Targets: []Target{{Name: "server", Address: address, CaBundle: bundle}}})
+		require.NoError(t, err)
+		require.Equal(t, true, result["verification"].(map[string]interface{})["verified"])
+	})
+}
+
+func TestGetResourcesProtocolVersions(t *testing.T) {
+	t.Parallel()
+
+	server, _ := startServer(t, &cryptotls.Config{MaxVersion: cryptotls.VersionTLS12})
+
+	result, err := getTarget(t, &TlsSpec{Targets: []Target{{Name: "server", Address: server.Listener.Addr().String()}}})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"TLS 1.2"}, result["versions"])
+	suites := result["cipher-suites"].(map[string]interface{})
+	require.Len(t, suites, 1)
+	require.Contains(t, suites["TLS 1.2"], "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	require.Contains(t, suites["TLS 1.2"], "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256")
+	// the test certificate is not in the system trust store
+	require.Equal(t, false, result["verification"].(map[string]interface{})["verified"])
+}
+
+func TestGetResourcesCipherSuites(t *testing.T) {
+	t.Parallel()
+
+	server, _ := startServer(t, &cryptotls.Config{
+		MaxVersion: cryptotls.VersionTLS12,
+		CipherSuites: []uint16{
+			cryptotls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			cryptotls.TLS_RSA_WITH_AES_128_CBC_SHA,
+		},
+	})
+
+	result, err := getTarget(t, &TlsSpec{Targets: []Target{{Name: "server", Address: server.Listener.Addr().String()}}})
+	require.NoError(t, err)
+	suites := result["cipher-suites"].(map[string]interface{})
+	require.ElementsMatch(t, []interface{}{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_RSA_WITH_AES_128_CBC_SHA"}, suites["TLS 1.2"])
+}
+
+func TestGetResourcesUnreachable(t *testing.T) {
+	t.Parallel()
+
+	server, _ := startServer(t, &cryptotls.Config{})
+	address := server.Listener.Addr().String()
+	server.Close()
+
+	result, err := getTarget(t, &TlsSpec{Timeout: "1s", Targets: []Target{{Name: "server", Address: address}}})
+	require.Error(t, err)
+	require.Empty(t, result)
+}
+
+func TestGetResourcesDeadline(t *testing.T) {
+	t.Parallel()
+
+	// the handshakes offering a single cipher suite stall until the client gives up
+	stall := make(chan struct{})
+	server, _ := startServer(t, &cryptotls.Config{
+		MinVersion: cryptotls.VersionTLS10,
+		GetConfigForClient: func(hello *cryptotls.ClientHelloInfo) (*cryptotls.Config, error) {
+			if len(hello.CipherSuites) == 1 {
+				select {
+				case <-hello.Context().Done():
+				case <-stall:
+				}
+			}
+			return nil, nil
+		},
+	})
+	t.Cleanup(func() { close(stall) })
+
+	start := time.Now()
+	result, err := getTarget(t, &TlsSpec{Timeout: "200ms", Targets: []Target{{Name: "server", Address: server.Listener.Addr().String()}}})
+	require.ErrorContains(t, err, "did not complete within 800ms")
+	require.Empty(t, result)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestGetResourcesFile(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	notAfter := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(255),
+		Subject:      pkix.Name{CommonName: "expired.example.com", Organization: []string{"Lula"}},
+		DNSNames:     []string{"expired.example.com"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePEM(t, filepath.Join(dir, "expired.pem"), der)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.pem"), []byte("not a certificate"), 0600))
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	domain, err := CreateTlsDomain(&TlsSpec{
+		CaBundle: "expired.pem",
+		Targets: []Target{
+			{Name: "expired", File: "expired.pem"},
+			{Name: "empty", File: "empty.pem"},
+		},
+	})
+	require.NoError(t, err)
+	resources, err := domain.GetResources(ctx)
+	require.Error(t, err)
+	require.Equal(t, map[string]interface{}{}, resources["empty"])
+
+	result := resources["expired"].(map[string]interface{})
+	require.Equal(t, "", result["server-name"])
+	require.Equal(t, []interface{}{}, result["versions"])
+	require.Equal(t, map[string]interface{}{}, result["cipher-suites"])
+	verification := result["verification"].(map[string]interface{})
+	require.Equal(t, false, verification["verified"])
+	require.Contains(t, verification["error"], "expired")
+
+	cert := result["certificates"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "CN=expired.example.com,O=Lula", cert["subject"])
+	require.Equal(t, "expired.example.com", cert["common-name"])
+	require.Equal(t, "ff", cert["serial-number"])
+	require.Equal(t, []interface{}{"expired.example.com"}, cert["dns-names"])
+	require.Equal(t, notAfter.UTC().Format(time.RFC3339), cert["not-after"])
+	require.Equal(t, true, cert["expired"])
+	require.Equal(t, -2, cert["days-until-expiry"])
+	require.Equal(t, "ECDSA", cert["key-type"])
+	require.Equal(t, 256, cert["key-size"])
+	require.Equal(t, "ECDSA-SHA256", cert["signature-algorithm"])
+	require.Equal(t, false, cert["is-ca"])
+}
+
+func TestCreateTlsDomain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *TlsSpec
+		wantErr bool
+	}{
+		{name: "nil spec", spec: nil, wantErr: true},
+		{name: "no targets", spec: &TlsSpec{}, wantErr: true},
+		{name: "valid", spec: &TlsSpec{Timeout: "5s", Targets: []Target{{Name: "a", Address: "example.com:443"}, {Name: "b", File: "cert.pem"}}}},
+		{name: "missing name", spec: &TlsSpec{Targets: []Target{{Address: "example.com:443"}}}, wantErr: true},
+		{name: "duplicate name", spec: &TlsSpec{Targets: []Target{{Name: "a", Address: "example.com:443"}, {Name: "a", File: "cert.pem"}}}, wantErr: true},
+		{name: "no address or file", spec: &TlsSpec{Targets: []Target{{Name: "a"}}}, wantErr: true},
+		{name: "address and file", spec: &TlsSpec{Targets: []Target{{Name: "a", Address: "example.com:443", File: "cert.pem"}}}, wantErr: true},
+		{name: "address without port", spec: &TlsSpec{Targets: []Target{{Name: "a", Address: "example.com"}}}, wantErr: true},
+		{name: "invalid timeout", spec: &TlsSpec{Timeout: "5", Targets: []Target{{Name: "a", Address: "example.com:443"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateTlsDomain(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTlsDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
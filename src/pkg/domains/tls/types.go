@@ -0,0 +1,91 @@
+package tls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// defaultTimeout limits each connection to a target
+const defaultTimeout = 10 * time.Second
+
+// TlsDomain connects to TLS endpoints, or reads PEM certificates, and reports the protocol
+// versions, cipher suites and certificate chain
+type TlsDomain struct {
+	Spec *TlsSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// TlsSpec is the user-defined specification of targets to inspect
+type TlsSpec struct {
+	Targets []Target `json:"targets" yaml:"targets"`
+	// CaBundle is a PEM file of the certificates the chains are verified against, defaults to the system roots
+	CaBundle string `json:"ca-bundle,omitempty" yaml:"ca-bundle,omitempty"`
+	// Timeout for each connection, defaults to 10s. Each address is inspected for at most targetTimeoutFactor times the timeout
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Target is a single endpoint or certificate file. Exactly one of Address or File must be specified.
+type Target struct {
+	// Name is the key of the target in the domain resources
+	Name string `json:"name" yaml:"name"`
+	// Address is the host:port of the endpoint
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+	// File is a local path or URL of a PEM encoded certificate chain, leaf first
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// ServerName is the name sent with SNI and verified against the certificate. Defaults to
+	// the host of the address, and is not verified for files unless set.
+	ServerName string `json:"server-name,omitempty" yaml:"server-name,omitempty"`
+	// CaBundle overrides the trust bundle of the spec for this target
+	CaBundle string `json:"ca-bundle,omitempty" yaml:"ca-bundle,omitempty"`
+}
+
+func CreateTlsDomain(spec *TlsSpec) (types.Domain, error) {
+	if spec == nil {
+		return nil, errors.New("spec is required")
+	}
+	if len(spec.Targets) == 0 {
+		return nil, errors.New("some targets must be specified")
+	}
+
+	var errs error
+	if spec.Timeout != "" {
+		if _, err := time.ParseDuration(spec.Timeout); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid timeout: %w", err))
+		}
+	}
+
+	names := make(map[string]bool, len(spec.Targets))
+	for _, target := range spec.Targets {
+		if target.Name == "" {
+			errs = errors.Join(errs, errors.New("target name cannot be empty"))
+		} else if names[target.Name] {
+			errs = errors.Join(errs, fmt.Errorf("target name %s must be unique", target.Name))
+		}
+		names[target.Name] = true
+
+		switch {
+		case (target.Address == "") == (target.File == ""):
+			errs = errors.Join(errs, fmt.Errorf("target %s: exactly one of address or file must be specified", target.Name))
+		case target.Address != "":
+			if _, _, err := net.SplitHostPort(target.Address); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("target %s: invalid address: %w", target.Name, err))
+			}
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return TlsDomain{Spec: spec}, nil
+}
+
+// GetResources returns the inspection of each target keyed by target name
+func (d TlsDomain) GetResources(ctx context.Context) (types.DomainResources, error) {
+	return d.inspectTargets(ctx)
+}
+
+// IsExecutable returns false; the domain only connects to the targets.
+func (d TlsDomain) IsExecutable() bool { return false }
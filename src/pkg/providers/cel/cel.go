@@ -0,0 +1,245 @@
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/ext"
+
+	celtypes "github.com/google/cel-go/common/types"
+
+	"github.com/mike-winberry/lulalib/src/pkg/message"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+const (
+	// resourcesVariable is the name the domain resources are available as
+	resourcesVariable = "resources"
+	// variablesVariable is the name the values of the spec variables are available as
+	variablesVariable = "variables"
+	// objectVariable is the name the current item of a for-each list is available as
+	objectVariable = "object"
+	// interruptCheckFrequency is the number of comprehension iterations between checks for cancellation
+	interruptCheckFrequency = 100
+)
+
+// variable is a compiled CelVariable
+type variable struct {
+	name    string
+	program cel.Program
+}
+
+// validation is a compiled CelValidation
+type validation struct {
+	CelValidation
+	expression        cel.Program
+	forEach           cel.Program
+	messageExpression cel.Program
+}
+
+// newEnv returns the environment the expressions are compiled in, which declares the resources, variables,
+// and object, and includes the string, list, set, math, encoder and optional extensions
+func newEnv() (*cel.Env, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(resourcesVariable, cel.DynType),
+		cel.Variable(variablesVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(objectVariable, cel.DynType),
+		cel.CrossTypeNumericComparisons(true),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+		ext.Math(),
+		ext.Encoders(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cel environment: %w", err)
+	}
+	return env, nil
+}
+
+// compile compiles the expression and checks it evaluates to the expected type, or dyn. A nil expected
+// type accepts any type.
+func compile(env *cel.Env, expression string, expected *cel.Type) (cel.Program, error) {
+	if expression == "" {
+		return nil, ErrEmptyExpression
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileCel, issues.Err())
+	}
+	if expected != nil && !ast.OutputType().IsExactType(cel.DynType) && !expected.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("%w: expression returns %s, not %s", ErrCompileCel, ast.OutputType(), expected)
+	}
+
+	program, err := env.Program(ast, cel.InterruptCheckFrequency(interruptCheckFrequency))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileCel, err)
+	}
+	return program, nil
+}
+
+// evaluate evaluates the variables and then each validation against the resources. Validations are passing
+// results if true and failing results if false or if they cannot be evaluated. Validations with a for-each
+// list are a result for each item of the list.
+func (c CelProvider) evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
+	var result types.Result
+
+	data, err := normalize(resources)
+	if err != nil {
+		return result, err
+	}
+
+	values := make(map[string]interface{}, len(c.variables))
+	activation := map[string]interface{}{
+		resourcesVariable: data,
+		variablesVariable: values,
+	}
+	for _, v := range c.variables {
+		val, _, err := v.program.ContextEval(ctx, activation)
+		if err != nil {
+			return result, fmt.Errorf("%w: variable %s: %w", ErrEvaluateCel, v.name, err)
+		}
+		values[v.name] = val
+	}
+
+	for _, v := range c.validations {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if v.forEach == nil {
+			evaluateValidation(ctx, v, activation, &result)
+		} else {
+			evaluateForEach(ctx, v, activation, &result)
+		}
+	}
+
+	return result, nil
+}
+
+// evaluateValidation adds the result of a validation without a for-each list
+func evaluateValidation(ctx context.Context, v validation, activation map[string]interface{}, result *types.Result) {
+	pass, msg, err := v.evaluate(ctx, activation)
+	switch {
+	case err != nil:
+		message.Debugf("Error while evaluating validation %s: %v", v.Name, err)
+		result.Failing += 1
+		result.AddObservation(v.Name, fmt.Sprintf("ERROR: %s", err))
+	case pass:
+		result.Passing += 1
+		result.AddObservation(v.Name, "PASS")
+	default:
+		result.Failing += 1
+		result.AddObservation(v.Name, fmt.Sprintf("FAIL: %s", msg))
+	}
+}
+
+// evaluateForEach adds a result for each item of the for-each list of a validation. The validation is
+// observed under its name, and each failing item under name[i]. An empty list is a single passing result.
+func evaluateForEach(ctx context.Context, v validation, activation map[string]interface{}, result *types.Result) {
+	list, err := v.items(ctx, activation)
+	if err != nil {
+		message.Debugf("Error while evaluating for-each of validation %s: %v", v.Name, err)
+		result.Failing += 1
+		result.AddObservation(v.Name, fmt.Sprintf("ERROR: %s", err))
+		return
+	}
+
+	itemActivation := make(map[string]interface{}, len(activation)+1)
+	for k, val := range activation {
+		itemActivation[k] = val
+	}
+
+	result.AddItemResults(v.Name, len(list), func(i int, key string) bool {
+		itemActivation[objectVariable] = list[i]
+		pass, msg, err := v.evaluate(ctx, itemActivation)
+		switch {
+		case err != nil:
+			message.Debugf("Error while evaluating validation %s: %v", key, err)
+			result.AddObservation(key, fmt.Sprintf("ERROR: %s", err))
+			return false
+		case !pass:
+			result.AddObservation(key, msg)
+		}
+		return pass
+	})
+}
+
+// evaluate evaluates the expression of the validation, and its message if the expression is false
+func (v validation) evaluate(ctx context.Context, activation map[string]interface{}) (bool, string, error) {
+	val, _, err := v.expression.ContextEval(ctx, activation)
+	if err != nil {
+		return false, "", fmt.Errorf("%w: %w", ErrEvaluateCel, err)
+	}
+	pass, ok := val.(celtypes.Bool)
+	if !ok {
+		return false, "", fmt.Errorf("%w: expression returned %s, not bool", ErrEvaluateCel, val.Type())
+	}
+	if pass {
+		return true, "", nil
+	}
+	return false, v.message(ctx, activation), nil
+}
+
+// message returns the message of a validation that is false. If the message expression cannot be evaluated,
+// the default message is returned along with the error.
+func (v validation) message(ctx context.Context, activation map[string]interface{}) string {
+	defaultMessage := v.Message
+	if defaultMessage == "" {
+		defaultMessage = fmt.Sprintf("failed expression: %s", v.Expression)
+	}
+	if v.messageExpression == nil {
+		return defaultMessage
+	}
+
+	val, _, err := v.messageExpression.ContextEval(ctx, activation)
+	if err != nil {
+		return fmt.Sprintf("%s (message-expression error: %s)", defaultMessage, err)
+	}
+	msg, ok := val.(celtypes.String)
+	if !ok {
+		return fmt.Sprintf("%s (message-expression returned %s, not string)", defaultMessage, val.Type())
+	}
+	return string(msg)
+}
+
+// items evaluates the for-each expression of the validation and returns the items of the list
+func (v validation) items(ctx context.Context, activation map[string]interface{}) ([]ref.Val, error) {
+	val, _, err := v.forEach.ContextEval(ctx, activation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEvaluateCel, err)
+	}
+	lister, ok := val.(traits.Lister)
+	if !ok {
+		return nil, fmt.Errorf("%w: for-each returned %s, not list", ErrEvaluateCel, val.Type())
+	}
+
+	size, ok := lister.Size().(celtypes.Int)
+	if !ok {
+		return nil, fmt.Errorf("%w: for-each returned a list without a size", ErrEvaluateCel)
+	}
+	items := make([]ref.Val, 0, int(size))
+	for i := celtypes.Int(0); i < size; i++ {
+		items = append(items, lister.Get(i))
+	}
+	return items, nil
+}
+
+// normalize converts the resources to their JSON representation, so that any typed values provided by
+// the domain are available as maps, lists, and primitives
+func normalize(resources types.DomainResources) (interface{}, error) {
+	b, err := json.Marshal(resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert resources: %w", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("failed to convert resources: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,153 @@
+package cel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/pkg/providers/cel"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var resources = types.DomainResources{
+	"pods": []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "a", "labels": map[string]interface{}{"lula": "true"}},
+			"spec":     map[string]interface{}{"replicas": 1},
+		},
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "b", "labels": map[string]interface{}{"lula": "false"}},
+			"spec":     map[string]interface{}{"replicas": 3},
+		},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		spec         *cel.CelSpec
+		passing      int
+		failing      int
+		observations map[string]string
+	}{
+		{
+			name: "passing expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "has-pods", Expression: "size(resources.pods) == 2"},
+				},
+			},
+			passing:      1,
+			observations: map[string]string{"has-pods": "PASS"},
+		},
+		{
+			name: "failing expression with default message",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "no-pods", Expression: "size(resources.pods) == 0"},
+				},
+			},
+			failing:      1,
+			observations: map[string]string{"no-pods": "FAIL: failed expression: size(resources.pods) == 0"},
+		},
+		{
+			name: "failing expression with message",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "no-pods", Expression: "size(resources.pods) == 0", Message: "pods are not allowed"},
+				},
+			},
+			failing:      1,
+			observations: map[string]string{"no-pods": "FAIL: pods are not allowed"},
+		},
+		{
+			name: "variables",
+			spec: &cel.CelSpec{
+				Variables: []cel.CelVariable{
+					{Name: "pods", Expression: "resources.pods"},
+					{Name: "replicas", Expression: "variables.pods.map(p, p.spec.replicas)"},
+				},
+				Validations: []cel.CelValidation{
+					{Name: "replicas", Expression: "math.greatest(variables.replicas) <= 3"},
+				},
+			},
+			passing:      1,
+			observations: map[string]string{"replicas": "PASS"},
+		},
+		{
+			name: "for-each with message-expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{
+						Name:              "labeled",
+						ForEach:           "resources.pods",
+						Expression:        "object.metadata.labels.lula == 'true'",
+						MessageExpression: "'pod ' + object.metadata.name + ' is not labeled'",
+					},
+				},
+			},
+			passing: 1,
+			failing: 1,
+			observations: map[string]string{
+				"labeled":    "FAIL: 1 of 2 items",
+				"labeled[1]": "pod b is not labeled",
+			},
+		},
+		{
+			name: "for-each of empty list",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "none", ForEach: "resources.pods.filter(p, p.metadata.name == 'c')", Expression: "false"},
+				},
+			},
+			passing:      1,
+			observations: map[string]string{"none": "PASS: 0 items"},
+		},
+		{
+			name: "evaluation error",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "missing", Expression: "resources.deployments.size() > 0"},
+					{Name: "has-pods", Expression: "has(resources.pods)"},
+				},
+			},
+			passing: 1,
+			failing: 1,
+			observations: map[string]string{
+				"missing":  "ERROR: failed to evaluate cel expression: no such key: deployments",
+				"has-pods": "PASS",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := cel.CreateCelProvider(context.Background(), tt.spec)
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(context.Background(), resources)
+			require.NoError(t, err)
+			require.Equal(t, tt.passing, result.Passing)
+			require.Equal(t, tt.failing, result.Failing)
+			require.Equal(t, tt.observations, result.Observations)
+		})
+	}
+}
+
+func TestEvaluateVariableError(t *testing.T) {
+	t.Parallel()
+
+	provider, err := cel.CreateCelProvider(context.Background(), &cel.CelSpec{
+		Variables:   []cel.CelVariable{{Name: "missing", Expression: "resources.deployments"}},
+		Validations: []cel.CelValidation{{Name: "any", Expression: "true"}},
+	})
+	require.NoError(t, err)
+
+	_, err = provider.Evaluate(context.Background(), resources)
+	require.ErrorIs(t, err, cel.ErrEvaluateCel)
+}
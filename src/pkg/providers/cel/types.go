@@ -0,0 +1,156 @@
+package cel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var (
+	ErrNilSpec          = errors.New("spec is nil")
+	ErrNoValidations    = errors.New("some validations must be specified")
+	ErrCompileCel       = errors.New("failed to compile cel expression")
+	ErrEvaluateCel      = errors.New("failed to evaluate cel expression")
+	ErrEmptyName        = errors.New("name cannot be empty")
+	ErrEmptyExpression  = errors.New("expression cannot be empty")
+	ErrDuplicateName    = errors.New("names must be unique")
+	ErrMessageAndMsgExp = errors.New("message and message-expression cannot both be specified")
+)
+
+type CelProvider struct {
+	// Spec is the specification of the CEL expressions
+	Spec *CelSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// variables and validations are the compiled programs of the spec, in the same order
+	variables   []variable
+	validations []validation
+}
+
+func CreateCelProvider(_ context.Context, spec *CelSpec) (types.Provider, error) {
+	// Check validity of spec
+	if spec == nil {
+		return nil, ErrNilSpec
+	}
+
+	if len(spec.Validations) == 0 {
+		return nil, ErrNoValidations
+	}
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	variableNames := make(map[string]bool, len(spec.Variables))
+	variables := make([]variable, 0, len(spec.Variables))
+	for _, v := range spec.Variables {
+		if v.Name == "" {
+			errs = errors.Join(errs, fmt.Errorf("variable %w", ErrEmptyName))
+			continue
+		}
+		if variableNames[v.Name] {
+			errs = errors.Join(errs, fmt.Errorf("variable %s: %w", v.Name, ErrDuplicateName))
+			continue
+		}
+		variableNames[v.Name] = true
+
+		program, err := compile(env, v.Expression, nil)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("variable %s: %w", v.Name, err))
+			continue
+		}
+		variables = append(variables, variable{name: v.Name, program: program})
+	}
+
+	validationNames := make(map[string]bool, len(spec.Validations))
+	validations := make([]validation, 0, len(spec.Validations))
+	for _, v := range spec.Validations {
+		compiled, err := compileValidation(env, v)
+		if err == nil && validationNames[v.Name] {
+			err = fmt.Errorf("validation %s: %w", v.Name, ErrDuplicateName)
+		}
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		validationNames[v.Name] = true
+		validations = append(validations, compiled)
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+
+	return CelProvider{
+		Spec:        spec,
+		variables:   variables,
+		validations: validations,
+	}, nil
+}
+
+// compileValidation compiles the expressions of the validation
+func compileValidation(env *cel.Env, v CelValidation) (validation, error) {
+	if v.Name == "" {
+		return validation{}, fmt.Errorf("validation %w", ErrEmptyName)
+	}
+	if v.Message != "" && v.MessageExpression != "" {
+		return validation{}, fmt.Errorf("validation %s: %w", v.Name, ErrMessageAndMsgExp)
+	}
+
+	compiled := validation{CelValidation: v}
+	var err error
+	if compiled.expression, err = compile(env, v.Expression, cel.BoolType); err != nil {
+		return validation{}, fmt.Errorf("validation %s: %w", v.Name, err)
+	}
+	if v.ForEach != "" {
+		if compiled.forEach, err = compile(env, v.ForEach, cel.ListType(cel.DynType)); err != nil {
+			return validation{}, fmt.Errorf("validation %s for-each: %w", v.Name, err)
+		}
+	}
+	if v.MessageExpression != "" {
+		if compiled.messageExpression, err = compile(env, v.MessageExpression, cel.StringType); err != nil {
+			return validation{}, fmt.Errorf("validation %s message-expression: %w", v.Name, err)
+		}
+	}
+	return compiled, nil
+}
+
+func (c CelProvider) Evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
+	return c.evaluate(ctx, resources)
+}
+
+// CelSpec is the specification of the CEL expressions, required if the provider type is cel
+type CelSpec struct {
+	// Optional: Variables are evaluated in order, and are available to the later variables and the
+	// validations as variables.<name>
+	Variables []CelVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Required: Validations are the boolean expressions the resources are validated with
+	Validations []CelValidation `json:"validations" yaml:"validations"`
+}
+
+// CelVariable is a named expression, whose value is available to the validations
+type CelVariable struct {
+	// Required: Name of the variable
+	Name string `json:"name" yaml:"name"`
+	// Required: Expression of the value of the variable
+	Expression string `json:"expression" yaml:"expression"`
+}
+
+// CelValidation is a named boolean expression, which is a passing result if true and a failing result if false
+type CelValidation struct {
+	// Required: Name of the validation, which is the key of its observations
+	Name string `json:"name" yaml:"name"`
+	// Required: Expression that evaluates to true if the resources are valid
+	Expression string `json:"expression" yaml:"expression"`
+	// Optional: ForEach is an expression of a list, and the expression is evaluated for each item of
+	// the list as object, each of which is a result
+	ForEach string `json:"for-each,omitempty" yaml:"for-each,omitempty"`
+	// Optional: Message observed when the expression is false
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Optional: MessageExpression is an expression of the message observed when the expression is false
+	MessageExpression string `json:"message-expression,omitempty" yaml:"message-expression,omitempty"`
+}
@@ -0,0 +1,135 @@
+package cel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mike-winberry/lulalib/src/pkg/providers/cel"
+)
+
+func TestCreateCelProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *cel.CelSpec
+		wantErr bool
+	}{
+		{
+			name: "valid spec",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "has-pods", Expression: "size(resources.pods) > 0"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid spec with variables, for-each and message-expression",
+			spec: &cel.CelSpec{
+				Variables: []cel.CelVariable{
+					{Name: "pods", Expression: "resources.pods"},
+				},
+				Validations: []cel.CelValidation{
+					{
+						Name:              "labeled",
+						ForEach:           "variables.pods",
+						Expression:        "object.metadata.labels.lula == 'true'",
+						MessageExpression: "'pod ' + object.metadata.name + ' is not labeled'",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "nil spec",
+			spec:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "no validations",
+			spec:    &cel.CelSpec{},
+			wantErr: true,
+		},
+		{
+			name: "empty name",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Expression: "true"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "empty"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate validation names",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{
+					{Name: "a", Expression: "true"},
+					{Name: "a", Expression: "false"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate variable names",
+			spec: &cel.CelSpec{
+				Variables: []cel.CelVariable{
+					{Name: "a", Expression: "1"},
+					{Name: "a", Expression: "2"},
+				},
+				Validations: []cel.CelValidation{{Name: "a", Expression: "true"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "invalid", Expression: "resources.pods >"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-bool expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "string", Expression: "'true'"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-list for-each",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "for-each", ForEach: "1", Expression: "true"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-string message-expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "message", Expression: "true", MessageExpression: "1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "message and message-expression",
+			spec: &cel.CelSpec{
+				Validations: []cel.CelValidation{{Name: "message", Expression: "true", Message: "a", MessageExpression: "'b'"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cel.CreateCelProvider(context.Background(), tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateCelProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
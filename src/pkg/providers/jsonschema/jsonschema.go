@@ -0,0 +1,244 @@
+package jsonschema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	jschema "github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	textmessage "golang.org/x/text/message"
+
+	"github.com/mike-winberry/lulalib/src/pkg/message"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// resourcesKey is the observation key of the resources, if no targets are specified
+const resourcesKey = "resources"
+
+var (
+	// segmentPattern matches a segment of a target path, a key followed by any number of indices
+	segmentPattern = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+	// indexPattern matches an index of a segment of a target path
+	indexPattern = regexp.MustCompile(`\[(\d+)\]`)
+	// printer formats the messages of the schema errors
+	printer = textmessage.NewPrinter(language.English)
+)
+
+// evaluate validates the resources, or each of the targets, against the schema. Each validated value is a
+// passing result if it is valid and a failing result if it is not, or if the target cannot be found.
+func (j JsonSchemaProvider) evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
+	var result types.Result
+
+	schema, err := j.compiledSchema(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	data, err := normalize(resources)
+	if err != nil {
+		return result, fmt.Errorf("failed to convert resources: %w", err)
+	}
+
+	if len(j.Spec.Targets) == 0 {
+		validate(schema, resourcesKey, data, &result)
+		return result, nil
+	}
+
+	for _, target := range j.Spec.Targets {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		value, err := lookup(data, target.Path)
+		if err != nil {
+			message.Debugf("Error while looking up target %s: %v", target.Path, err)
+			result.Failing += 1
+			result.AddObservation(target.Path, fmt.Sprintf("ERROR: %s", err))
+			continue
+		}
+
+		if !target.ForEach {
+			validate(schema, target.Path, value, &result)
+			continue
+		}
+
+		items, ok := value.([]interface{})
+		if !ok {
+			result.Failing += 1
+			result.AddObservation(target.Path, fmt.Sprintf("ERROR: %s", ErrTargetNotAnArray))
+			continue
+		}
+		validateItems(schema, target.Path, items, &result)
+	}
+
+	return result, nil
+}
+
+// validate adds the result of validating a value, observed under its key as PASS, or as FAIL with each
+// of its errors observed under the key followed by the JSON pointer of the invalid value
+func validate(schema *jschema.Schema, key string, value any, result *types.Result) {
+	errs, err := validateValue(schema, value)
+	switch {
+	case err != nil:
+		result.Failing += 1
+		result.AddObservation(key, fmt.Sprintf("ERROR: %s", err))
+	case len(errs) > 0:
+		result.Failing += 1
+		result.AddObservation(key, fmt.Sprintf("FAIL: %d errors", len(errs)))
+		addErrors(key, errs, result)
+	default:
+		result.Passing += 1
+		result.AddObservation(key, "PASS")
+	}
+}
+
+// validateItems adds a result for each item of an array. The array is observed under its key, and each
+// invalid item under key[i]. An empty array is a single passing result.
+func validateItems(schema *jschema.Schema, key string, items []interface{}, result *types.Result) {
+	result.AddItemResults(key, len(items), func(i int, itemKey string) bool {
+		errs, err := validateValue(schema, items[i])
+		switch {
+		case err != nil:
+			result.AddObservation(itemKey, fmt.Sprintf("ERROR: %s", err))
+			return false
+		case len(errs) > 0:
+			result.AddObservation(itemKey, fmt.Sprintf("FAIL: %d errors", len(errs)))
+			addErrors(itemKey, errs, result)
+			return false
+		default:
+			return true
+		}
+	})
+}
+
+// schemaError is an error of the validation of a value against the schema
+type schemaError struct {
+	// pointer is the JSON pointer of the invalid value, relative to the validated value
+	pointer string
+	message string
+}
+
+// validateValue validates the value against the schema and returns the errors of the validation, sorted
+// by their pointer, or an error if the value could not be validated
+func validateValue(schema *jschema.Schema, value any) ([]schemaError, error) {
+	err := schema.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+
+	var errs []schemaError
+	collectErrors(validationErr, &errs)
+	sort.SliceStable(errs, func(i, k int) bool {
+		return errs[i].pointer < errs[k].pointer
+	})
+	return errs, nil
+}
+
+// collectErrors appends the leaf errors of the validation error, which are those that are not caused by
+// other errors
+func collectErrors(err *jschema.ValidationError, errs *[]schemaError) {
+	if len(err.Causes) == 0 {
+		*errs = append(*errs, schemaError{
+			pointer: pointer(err.InstanceLocation),
+			message: err.ErrorKind.LocalizedString(printer),
+		})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectErrors(cause, errs)
+	}
+}
+
+// addErrors observes the errors of a value under key#pointer. Errors of the same value are joined.
+func addErrors(key string, errs []schemaError, result *types.Result) {
+	messages := make(map[string][]string)
+	var pointers []string
+	for _, err := range errs {
+		if _, ok := messages[err.pointer]; !ok {
+			pointers = append(pointers, err.pointer)
+		}
+		messages[err.pointer] = append(messages[err.pointer], err.message)
+	}
+	for _, p := range pointers {
+		result.AddObservation(fmt.Sprintf("%s#%s", key, p), strings.Join(messages[p], "; "))
+	}
+}
+
+// pointer returns the JSON pointer of the tokens of an instance location
+func pointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		token = strings.ReplaceAll(token, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(token, "/", "~1"))
+	}
+	return sb.String()
+}
+
+// pathPart is a key of an object or an index of an array in a target path
+type pathPart struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath splits a target path, e.g. config.spec.containers[0], into its keys and indices
+func parsePath(path string) ([]pathPart, error) {
+	var parts []pathPart
+	for _, segment := range strings.Split(path, ".") {
+		match := segmentPattern.FindStringSubmatch(segment)
+		if match == nil {
+			return nil, fmt.Errorf("%w %q: segment %q must be a key followed by any indices, e.g. items[0]", ErrInvalidPath, path, segment)
+		}
+		parts = append(parts, pathPart{key: match[1], isKey: true})
+		for _, index := range indexPattern.FindAllStringSubmatch(match[2], -1) {
+			i, err := strconv.Atoi(index[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: %w", ErrInvalidPath, path, err)
+			}
+			parts = append(parts, pathPart{index: i})
+		}
+	}
+	return parts, nil
+}
+
+// lookup returns the value at the target path of the data
+func lookup(data any, path string) (any, error) {
+	parts, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := data
+	for _, part := range parts {
+		if part.isKey {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is not an object", ErrTargetNotFound, part.key)
+			}
+			if value, ok = object[part.key]; !ok {
+				return nil, fmt.Errorf("%w: %s does not exist", ErrTargetNotFound, part.key)
+			}
+			continue
+		}
+
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: [%d] is not an index of an array", ErrTargetNotFound, part.index)
+		}
+		if part.index >= len(array) {
+			return nil, fmt.Errorf("%w: [%d] is out of range", ErrTargetNotFound, part.index)
+		}
+		value = array[part.index]
+	}
+	return value, nil
+}
@@ -0,0 +1,198 @@
+package jsonschema_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/pkg/providers/jsonschema"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var resources = types.DomainResources{
+	"pods": []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "a", "labels": map[string]interface{}{"lula": "true"}},
+			"spec":     map[string]interface{}{"replicas": 1},
+		},
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"lula": "false"}},
+			"spec":     map[string]interface{}{"replicas": 5},
+		},
+	},
+	"config": map[string]interface{}{
+		"tls": map[string]interface{}{"enabled": true, "min-version": "1.2"},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		spec         *jsonschema.JsonSchemaSpec
+		passing      int
+		failing      int
+		observations map[string]string
+	}{
+		{
+			name: "valid resources",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema: map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"pods", "config"},
+				},
+			},
+			passing:      1,
+			observations: map[string]string{"resources": "PASS"},
+		},
+		{
+			name: "invalid target",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"enabled":     map[string]interface{}{"const": true},
+						"min-version": map[string]interface{}{"enum": []interface{}{"1.3"}},
+					},
+				},
+				Targets: []jsonschema.JsonSchemaTarget{{Path: "config.tls"}},
+			},
+			failing: 1,
+			observations: map[string]string{
+				"config.tls":              "FAIL: 1 errors",
+				"config.tls#/min-version": "value must be '1.3'",
+			},
+		},
+		{
+			name: "for-each target with schema file",
+			spec: &jsonschema.JsonSchemaSpec{
+				SchemaFile: "pod.schema.yaml",
+				Targets:    []jsonschema.JsonSchemaTarget{{Path: "pods", ForEach: true}},
+			},
+			passing: 1,
+			failing: 1,
+			observations: map[string]string{
+				"pods":                          "FAIL: 1 of 2 items",
+				"pods[1]":                       "FAIL: 3 errors",
+				"pods[1]#/metadata":             "missing property 'name'",
+				"pods[1]#/metadata/labels/lula": "value must be 'true'",
+				"pods[1]#/spec/replicas":        "maximum: got 5, want 3",
+			},
+		},
+		{
+			name: "indexed target",
+			spec: &jsonschema.JsonSchemaSpec{
+				SchemaFile: "pod.schema.yaml",
+				Targets:    []jsonschema.JsonSchemaTarget{{Path: "pods[0]"}},
+			},
+			passing:      1,
+			observations: map[string]string{"pods[0]": "PASS"},
+		},
+		{
+			name: "missing target",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema:  map[string]interface{}{"type": "object"},
+				Targets: []jsonschema.JsonSchemaTarget{{Path: "deployments"}, {Path: "config.tls", ForEach: true}},
+			},
+			failing: 2,
+			observations: map[string]string{
+				"deployments": "ERROR: target not found: deployments does not exist",
+				"config.tls":  "ERROR: target is not an array",
+			},
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := jsonschema.CreateJsonSchemaProvider(ctx, tt.spec)
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(ctx, resources)
+			require.NoError(t, err)
+			require.Equal(t, tt.passing, result.Passing)
+			require.Equal(t, tt.failing, result.Failing)
+			require.Equal(t, tt.observations, result.Observations)
+		})
+	}
+}
+
+func TestEvaluateInvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	provider, err := jsonschema.CreateJsonSchemaProvider(context.Background(), &jsonschema.JsonSchemaSpec{
+		Schema: map[string]interface{}{"type": "unknown"},
+	})
+	require.NoError(t, err)
+
+	_, err = provider.Evaluate(context.Background(), resources)
+	require.ErrorIs(t, err, jsonschema.ErrCompileSchema)
+}
+
+func TestEvaluateRemoteRefs(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"required": ["enabled"]}`))
+	}))
+	defer server.Close()
+
+	spec := func(allow bool) *jsonschema.JsonSchemaSpec {
+		return &jsonschema.JsonSchemaSpec{
+			Schema:          map[string]interface{}{"$ref": server.URL + "/tls.schema.json"},
+			Targets:         []jsonschema.JsonSchemaTarget{{Path: "config.tls"}},
+			AllowRemoteRefs: allow,
+		}
+	}
+
+	provider, err := jsonschema.CreateJsonSchemaProvider(context.Background(), spec(false))
+	require.NoError(t, err)
+	_, err = provider.Evaluate(context.Background(), resources)
+	require.ErrorIs(t, err, jsonschema.ErrCompileSchema)
+	require.ErrorContains(t, err, jsonschema.ErrRemoteRef.Error())
+
+	provider, err = jsonschema.CreateJsonSchemaProvider(context.Background(), spec(true))
+	require.NoError(t, err)
+	result, err := provider.Evaluate(context.Background(), resources)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+}
+
+func TestEvaluateCompilesOnce(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	otherDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.schema.json"), []byte(`{"required": ["enabled"]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(otherDir, "config.schema.json"), []byte(`{"required": ["missing"]}`), 0600))
+
+	provider, err := jsonschema.CreateJsonSchemaProvider(context.Background(), &jsonschema.JsonSchemaSpec{
+		SchemaFile: "config.schema.json",
+		Targets:    []jsonschema.JsonSchemaTarget{{Path: "config.tls"}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+	result, err := provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+
+	// the compiled schema is reused, so the file is not read again
+	require.NoError(t, os.Remove(filepath.Join(dir, "config.schema.json")))
+	result, err = provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+
+	// the schema is compiled again for another working directory
+	result, err = provider.Evaluate(context.WithValue(context.Background(), types.LulaValidationWorkDir, otherDir), resources)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Failing)
+}
@@ -0,0 +1,147 @@
+package jsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	jschema "github.com/santhosh-tekuri/jsonschema/v6"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// inlineSchemaName is the file name the inline schema is located at in the working directory, against
+// which its relative references are resolved
+const inlineSchemaName = "jsonschema-spec.schema.json"
+
+// loader loads the schemas referenced by the schema, in JSON or YAML. Local references are resolved against
+// the working directory, and remote references are only loaded if the spec allows them.
+type loader struct {
+	workDir     string
+	allowRemote bool
+}
+
+func (l loader) Load(url string) (any, error) {
+	if !network.IsFileLocal(url) && !l.allowRemote {
+		return nil, ErrRemoteRef
+	}
+	b, err := network.Fetch(url, network.WithBaseDir(l.workDir))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(b)
+}
+
+// compiledSchema returns the compiled schema of the spec, loading and compiling it only if it has not been
+// compiled for the working directory
+func (j JsonSchemaProvider) compiledSchema(ctx context.Context) (*jschema.Schema, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadSchema, err)
+	}
+
+	if j.cache != nil {
+		j.cache.mu.Lock()
+		defer j.cache.mu.Unlock()
+		if j.cache.schema != nil && j.cache.workDir == workDir {
+			return j.cache.schema, nil
+		}
+	}
+
+	schema, err := j.compile(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.cache != nil {
+		j.cache.workDir = workDir
+		j.cache.schema = schema
+	}
+	return schema, nil
+}
+
+// compile loads the schema of the spec, resolving its file and references against the working directory,
+// and compiles it, defaulting to draft 2020-12 if the schema does not specify $schema
+func (j JsonSchemaProvider) compile(workDir string) (*jschema.Schema, error) {
+	var err error
+	var location string
+	var doc any
+	if j.Spec.SchemaFile != "" {
+		location, err = schemaLocation(j.Spec.SchemaFile, workDir)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadSchema, j.Spec.SchemaFile, err)
+		}
+		b, err := network.Fetch(j.Spec.SchemaFile, network.WithBaseDir(workDir))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadSchema, j.Spec.SchemaFile, err)
+		}
+		if doc, err = unmarshal(b); err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadSchema, j.Spec.SchemaFile, err)
+		}
+	} else {
+		location = filepath.Join(workDir, inlineSchemaName)
+		if doc, err = normalize(j.Spec.Schema); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrLoadSchema, err)
+		}
+	}
+
+	compiler := jschema.NewCompiler()
+	compiler.DefaultDraft(jschema.Draft2020)
+	compiler.UseLoader(loader{workDir: workDir, allowRemote: j.Spec.AllowRemoteRefs})
+	if err := compiler.AddResource(location, doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileSchema, err)
+	}
+	schema, err := compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompileSchema, err)
+	}
+	return schema, nil
+}
+
+// schemaLocation returns the absolute location of the schema file, without its checksum, against which
+// its relative references are resolved
+func schemaLocation(file, workDir string) (string, error) {
+	u, _, err := network.ParseChecksum(file)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return u.String(), nil
+	}
+
+	path := u.Opaque
+	if path == "" {
+		path = filepath.Join(u.Host, u.Path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// unmarshal unmarshals a JSON or YAML document, keeping the precision of its numbers
+func unmarshal(b []byte) (any, error) {
+	b, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	return jschema.UnmarshalJSON(bytes.NewReader(b))
+}
+
+// normalize converts a value to its JSON representation, so that any typed values provided by the domain
+// are validated as objects, arrays, and primitives
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jschema.UnmarshalJSON(bytes.NewReader(b))
+}
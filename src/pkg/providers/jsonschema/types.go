@@ -0,0 +1,100 @@
+package jsonschema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	jschema "github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var (
+	ErrNilSpec          = errors.New("spec is nil")
+	ErrNoSchema         = errors.New("schema or schema-file must be specified")
+	ErrSchemaAndFile    = errors.New("schema and schema-file cannot both be specified")
+	ErrEmptyPath        = errors.New("target path cannot be empty")
+	ErrInvalidPath      = errors.New("invalid target path")
+	ErrDuplicateTarget  = errors.New("target paths must be unique")
+	ErrLoadSchema       = errors.New("failed to load schema")
+	ErrCompileSchema    = errors.New("failed to compile schema")
+	ErrRemoteRef        = errors.New("remote references require allow-remote-refs")
+	ErrTargetNotFound   = errors.New("target not found")
+	ErrTargetNotAnArray = errors.New("target is not an array")
+)
+
+type JsonSchemaProvider struct {
+	// Spec is the specification of the JSON Schema and the resources it validates
+	Spec *JsonSchemaSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// cache is the schema compiled from the spec, which is shared by copies of the provider
+	cache *schemaCache
+}
+
+// schemaCache holds the compiled schema of a provider, keyed by the working directory its schema file and
+// references were resolved against. The schema is loaded once per working directory for the lifetime of
+// the provider, so changes to its files are not picked up by later evaluations.
+type schemaCache struct {
+	mu      sync.Mutex
+	workDir string
+	schema  *jschema.Schema
+}
+
+func CreateJsonSchemaProvider(_ context.Context, spec *JsonSchemaSpec) (types.Provider, error) {
+	// Check validity of spec
+	if spec == nil {
+		return nil, ErrNilSpec
+	}
+
+	if spec.Schema == nil && spec.SchemaFile == "" {
+		return nil, ErrNoSchema
+	}
+
+	if spec.Schema != nil && spec.SchemaFile != "" {
+		return nil, ErrSchemaAndFile
+	}
+
+	paths := make(map[string]bool, len(spec.Targets))
+	for _, target := range spec.Targets {
+		if target.Path == "" {
+			return nil, ErrEmptyPath
+		}
+		if _, err := parsePath(target.Path); err != nil {
+			return nil, err
+		}
+		if paths[target.Path] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateTarget, target.Path)
+		}
+		paths[target.Path] = true
+	}
+
+	return JsonSchemaProvider{
+		Spec:  spec,
+		cache: &schemaCache{},
+	}, nil
+}
+
+func (j JsonSchemaProvider) Evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
+	return j.evaluate(ctx, resources)
+}
+
+// JsonSchemaSpec is the specification of the JSON Schema provider, required if the provider type is jsonschema
+type JsonSchemaSpec struct {
+	// Optional: Schema is an inline JSON Schema, required if schema-file is not specified
+	Schema map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// Optional: SchemaFile is the path or URL of a JSON or YAML JSON Schema, required if schema is not specified
+	SchemaFile string `json:"schema-file,omitempty" yaml:"schema-file,omitempty"`
+	// Optional: Targets are the subtrees of the resources that are validated, defaults to all of the resources
+	Targets []JsonSchemaTarget `json:"targets,omitempty" yaml:"targets,omitempty"`
+	// Optional: AllowRemoteRefs allows $refs to be loaded from http(s) URLs, which are otherwise rejected
+	AllowRemoteRefs bool `json:"allow-remote-refs,omitempty" yaml:"allow-remote-refs,omitempty"`
+}
+
+// JsonSchemaTarget is a subtree of the resources that is validated against the schema
+type JsonSchemaTarget struct {
+	// Required: Path of the subtree, e.g. pods or config.spec.containers[0]
+	Path string `json:"path" yaml:"path"`
+	// Optional: ForEach validates each item of the array at the path, each of which is a result
+	ForEach bool `json:"for-each,omitempty" yaml:"for-each,omitempty"`
+}
@@ -0,0 +1,89 @@
+package jsonschema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mike-winberry/lulalib/src/pkg/providers/jsonschema"
+)
+
+func TestCreateJsonSchemaProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		spec    *jsonschema.JsonSchemaSpec
+		wantErr bool
+	}{
+		{
+			name: "valid spec with schema",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema: map[string]interface{}{"type": "object"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid spec with schema file and targets",
+			spec: &jsonschema.JsonSchemaSpec{
+				SchemaFile: "pod.schema.yaml",
+				Targets: []jsonschema.JsonSchemaTarget{
+					{Path: "pods", ForEach: true},
+					{Path: "config.spec.containers[0]"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "nil spec",
+			spec:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "no schema",
+			spec:    &jsonschema.JsonSchemaSpec{},
+			wantErr: true,
+		},
+		{
+			name: "schema and schema file",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema:     map[string]interface{}{"type": "object"},
+				SchemaFile: "pod.schema.yaml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty path",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema:  map[string]interface{}{"type": "object"},
+				Targets: []jsonschema.JsonSchemaTarget{{}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid path",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema:  map[string]interface{}{"type": "object"},
+				Targets: []jsonschema.JsonSchemaTarget{{Path: "pods[a]"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate paths",
+			spec: &jsonschema.JsonSchemaSpec{
+				Schema:  map[string]interface{}{"type": "object"},
+				Targets: []jsonschema.JsonSchemaTarget{{Path: "pods"}, {Path: "pods", ForEach: true}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonschema.CreateJsonSchemaProvider(context.Background(), tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateJsonSchemaProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
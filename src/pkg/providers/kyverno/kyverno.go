@@ -6,13 +6,17 @@ import (
 	"strings"
 
 	kjson "github.com/kyverno/kyverno-json/pkg/apis/policy/v1alpha1"
+	"github.com/kyverno/kyverno-json/pkg/matching"
 	"github.com/mike-winberry/lulalib/src/pkg/message"
 	"github.com/mike-winberry/lulalib/src/types"
 
 	jsonengine "github.com/kyverno/kyverno-json/pkg/json-engine"
 )
 
-func GetValidatedAssets(ctx context.Context, kyvernoPolicies *kjson.ValidatingPolicy, resources map[string]interface{}, output *KyvernoOutput) (types.Result, error) {
+// GetValidatedAssets evaluates the resources against the policies. Each rule is a passing or failing result,
+// observed under its policy/rule key, and each of its violations is observed under policy/rule[i]. Rules that
+// fail to evaluate are failing results, and their error is observed.
+func GetValidatedAssets(ctx context.Context, kyvernoPolicies []*kjson.ValidatingPolicy, resources map[string]interface{}, output *KyvernoOutput) (types.Result, error) {
 	var matchResult types.Result
 
 	if len(resources) == 0 {
@@ -20,7 +24,7 @@ func GetValidatedAssets(ctx context.Context, kyvernoPolicies *kjson.ValidatingPo
 		return matchResult, nil
 	}
 
-	if kyvernoPolicies == nil {
+	if len(kyvernoPolicies) == 0 {
 		return matchResult, fmt.Errorf("kyverno policy is not provided")
 	}
 
@@ -30,63 +34,48 @@ func GetValidatedAssets(ctx context.Context, kyvernoPolicies *kjson.ValidatingPo
 
 	validationSet := make(map[string]map[string]bool)
 	if output.Validation != "" {
-		validationPairs := strings.Split(output.Validation, ",")
-
-		for _, pair := range validationPairs {
-			pair := strings.Split(pair, ".")
-
-			if len(pair) != 2 {
+		for _, pair := range strings.Split(output.Validation, ",") {
+			policy, rule, ok := splitRuleKey(pair)
+			if !ok {
 				message.Debugf("Invalid validation pair: %v", pair)
 				continue
 			}
-
-			validationPolicy := strings.TrimSpace(pair[0])
-			validationRule := strings.TrimSpace(pair[1])
-			if _, ok := validationSet[validationPolicy]; !ok {
-				validationSet[validationPolicy] = make(map[string]bool)
+			if _, ok := validationSet[policy]; !ok {
+				validationSet[policy] = make(map[string]bool)
 			}
-			validationSet[validationPolicy][validationRule] = true
+			validationSet[policy][rule] = true
 		}
 	}
 
 	observationSet := make(map[string]map[string]bool)
-	if len(output.Observations) > 0 {
-		for _, observationPair := range output.Observations {
-			pair := strings.Split(observationPair, ".")
-
-			if len(pair) != 2 {
-				message.Debugf("Invalid validation pair: %v", pair)
-				continue
-			}
-
-			observationPolicy := strings.TrimSpace(pair[0])
-			observationRule := strings.TrimSpace(pair[1])
-			if _, ok := observationSet[observationPolicy]; !ok {
-				observationSet[observationPolicy] = make(map[string]bool)
-			}
-			observationSet[observationPolicy][observationRule] = true
+	for _, pair := range output.Observations {
+		policy, rule, ok := splitRuleKey(pair)
+		if !ok {
+			message.Debugf("Invalid observation pair: %v", pair)
+			continue
 		}
+		if _, ok := observationSet[policy]; !ok {
+			observationSet[policy] = make(map[string]bool)
+		}
+		observationSet[policy][rule] = true
 	}
 
-	policyarr := []*kjson.ValidatingPolicy{kyvernoPolicies}
-	message.Debug(*policyarr[0])
-
 	engine := jsonengine.New()
 	response := engine.Run(ctx, jsonengine.Request{
 		Resource: resources,
-		Policies: policyarr,
+		Policies: kyvernoPolicies,
 	})
 
-	observations := make(map[string]string)
-	for i, policy := range response.Policies {
-		for j, rule := range policy.Rules {
+	matchResult.Observations = make(map[string]string)
+	for _, policy := range response.Policies {
+		for _, rule := range policy.Rules {
+			failing := rule.Error != nil || len(rule.Violations) > 0
 			if rule.Error != nil {
-				message.Debugf("Error while evaluating rule: %v", rule.Error)
-				continue
+				message.Debugf("Error while evaluating rule %s/%s: %v", policy.Policy.Name, rule.Rule.Name, rule.Error)
 			}
 
 			if _, ok := validationSet[policy.Policy.Name][rule.Rule.Name]; output.Validation == "" || ok {
-				if len(rule.Violations) > 0 {
+				if failing {
 					matchResult.Failing += 1
 				} else {
 					matchResult.Passing += 1
@@ -94,15 +83,53 @@ func GetValidatedAssets(ctx context.Context, kyvernoPolicies *kjson.ValidatingPo
 			}
 
 			if _, ok := observationSet[policy.Policy.Name][rule.Rule.Name]; len(output.Observations) == 0 || ok {
-				if len(rule.Violations) > 0 {
-					observations[fmt.Sprintf("%s,%s-%d,%d", policy.Policy.Name, rule.Rule.Name, i, j)] = fmt.Sprintf("FAIL: %s", rule.Violations[0].Message)
-				} else {
-					observations[fmt.Sprintf("%s,%s-%d,%d", policy.Policy.Name, rule.Rule.Name, i, j)] = "PASS"
+				key := fmt.Sprintf("%s/%s", policy.Policy.Name, rule.Rule.Name)
+				switch {
+				case rule.Error != nil:
+					matchResult.AddObservation(key, fmt.Sprintf("ERROR: %s", rule.Error))
+				case len(rule.Violations) > 0:
+					matchResult.AddObservation(key, fmt.Sprintf("FAIL: %d violations", len(rule.Violations)))
+					for i, violation := range rule.Violations {
+						matchResult.AddObservation(types.ItemKey(key, i, len(rule.Violations)), violationData(violation))
+					}
+				default:
+					matchResult.AddObservation(key, "PASS")
 				}
 			}
 		}
 	}
 
-	matchResult.Observations = observations
 	return matchResult, nil
 }
+
+// splitRuleKey splits a policy/rule key into the policy and rule names. The policy.rule form is also
+// accepted if neither name contains a dot.
+func splitRuleKey(key string) (policy string, rule string, ok bool) {
+	pair := strings.SplitN(key, "/", 2)
+	if len(pair) != 2 {
+		pair = strings.Split(key, ".")
+		if len(pair) != 2 {
+			return "", "", false
+		}
+	}
+	return strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]), true
+}
+
+// violationData returns the message of the violation and the path and detail of each of the checks that failed
+func violationData(violation matching.Result) map[string]interface{} {
+	data := make(map[string]interface{})
+	if violation.Message != "" {
+		data["message"] = violation.Message
+	}
+	errs := make([]interface{}, 0, len(violation.ErrorList))
+	for _, err := range violation.ErrorList {
+		errs = append(errs, map[string]interface{}{
+			"path":   err.Field,
+			"detail": err.ErrorBody(),
+		})
+	}
+	if len(errs) > 0 {
+		data["errors"] = errs
+	}
+	return data
+}
@@ -0,0 +1,156 @@
+package kyverno_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kjson "github.com/kyverno/kyverno-json/pkg/apis/policy/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mike-winberry/lulalib/src/pkg/providers/kyverno"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var labelsPolicy = `
+apiVersion: json.kyverno.io/v1alpha1
+kind: ValidatingPolicy
+metadata:
+  name: labels
+spec:
+  rules:
+  - name: lula-label
+    assert:
+      all:
+      - message: pods must be labeled
+        check:
+          ~.pods:
+            metadata:
+              labels:
+                lula: "true"
+`
+
+var resources = types.DomainResources{
+	"pods": []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "a", "labels": map[string]interface{}{"lula": "true"}},
+			"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "nginx:latest"}}},
+		},
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "b", "labels": map[string]interface{}{"lula": "false"}},
+			"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "nginx:latest"}}},
+		},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	var policy kjson.ValidatingPolicy
+	require.NoError(t, yaml.Unmarshal([]byte(labelsPolicy), &policy))
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	provider, err := kyverno.CreateKyvernoProvider(ctx, &kyverno.KyvernoSpec{
+		Policies:    []*kjson.ValidatingPolicy{&policy},
+		PolicyFiles: []string{"policies.yaml"},
+	})
+	require.NoError(t, err)
+
+	result, err := provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Passing)
+	require.Equal(t, 3, result.Failing)
+
+	require.Equal(t, "FAIL: 1 violations", result.Observations["labels/lula-label"])
+	require.Equal(t, map[string]interface{}{
+		"message": "pods must be labeled",
+		"errors": []interface{}{
+			map[string]interface{}{
+				"path":   "all[0].check.~.pods[1].metadata.labels.lula",
+				"detail": `Invalid value: "false": Expected value: "true"`,
+			},
+		},
+	}, result.ObservationData["labels/lula-label[0]"])
+
+	// every violation is reported
+	require.Equal(t, "FAIL: 1 violations", result.Observations["images/no-latest"])
+	violation := result.ObservationData["images/no-latest[0]"].(map[string]interface{})
+	require.Len(t, violation["errors"], 2)
+
+	// rules that fail to evaluate are failing observations
+	require.Contains(t, result.Observations["errors/unknown-function"], "ERROR: ")
+}
+
+func TestEvaluateOutput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	provider, err := kyverno.CreateKyvernoProvider(ctx, &kyverno.KyvernoSpec{
+		PolicyFiles: []string{"policies.yaml"},
+		Output: &kyverno.KyvernoOutput{
+			Validation:   "errors/unknown-function",
+			Observations: []string{"images.no-latest"},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Passing)
+	require.Equal(t, 1, result.Failing)
+	require.Len(t, result.Observations, 2)
+	require.Contains(t, result.Observations, "images/no-latest")
+	require.Contains(t, result.Observations, "images/no-latest[0]")
+}
+
+func TestEvaluateLoadPolicies(t *testing.T) {
+	t.Parallel()
+
+	var policy kjson.ValidatingPolicy
+	require.NoError(t, yaml.Unmarshal([]byte(labelsPolicy), &policy))
+	policy.Name = "images"
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	provider, err := kyverno.CreateKyvernoProvider(ctx, &kyverno.KyvernoSpec{
+		Policy:      &policy,
+		PolicyFiles: []string{"policies.yaml"},
+	})
+	require.NoError(t, err)
+	_, err = provider.Evaluate(ctx, resources)
+	require.ErrorIs(t, err, kyverno.ErrDuplicatePolicy)
+
+	provider, err = kyverno.CreateKyvernoProvider(ctx, &kyverno.KyvernoSpec{
+		PolicyFiles: []string{"missing.yaml"},
+	})
+	require.NoError(t, err)
+	_, err = provider.Evaluate(ctx, resources)
+	require.ErrorIs(t, err, kyverno.ErrLoadPolicy)
+}
+
+func TestEvaluatePolicyCache(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	b, err := os.ReadFile(filepath.Join("testdata", "policies.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "policies.yaml"), b, 0o600))
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, workDir)
+	provider, err := kyverno.CreateKyvernoProvider(ctx, &kyverno.KyvernoSpec{
+		PolicyFiles: []string{"policies.yaml"},
+	})
+	require.NoError(t, err)
+	expected, err := provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+
+	// the policy files are loaded once per working directory
+	require.NoError(t, os.Remove(filepath.Join(workDir, "policies.yaml")))
+	result, err := provider.Evaluate(ctx, resources)
+	require.NoError(t, err)
+	require.Equal(t, expected, result)
+
+	_, err = provider.Evaluate(context.WithValue(context.Background(), types.LulaValidationWorkDir, t.TempDir()), resources)
+	require.ErrorIs(t, err, kyverno.ErrLoadPolicy)
+}
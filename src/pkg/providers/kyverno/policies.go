@@ -0,0 +1,112 @@
+package kyverno
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	kjson "github.com/kyverno/kyverno-json/pkg/apis/policy/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// validatingPolicyKind is the kind of the policies that can be evaluated
+const validatingPolicyKind = "ValidatingPolicy"
+
+// loadedPolicies returns the policies of the spec, loading its policy files only if they have not been
+// loaded for the working directory
+func (k KyvernoProvider) loadedPolicies(ctx context.Context) ([]*kjson.ValidatingPolicy, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadPolicy, err)
+	}
+
+	if k.cache != nil {
+		k.cache.mu.Lock()
+		defer k.cache.mu.Unlock()
+		if k.cache.policies != nil && k.cache.workDir == workDir {
+			return k.cache.policies, nil
+		}
+	}
+
+	policies, err := loadPolicies(k.Spec, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.cache != nil {
+		k.cache.workDir = workDir
+		k.cache.policies = policies
+	}
+	return policies, nil
+}
+
+// loadPolicies returns the inline policies of the spec followed by those of the policy files, and
+// returns an error if any two policies have the same name
+func loadPolicies(spec *KyvernoSpec, workDir string) ([]*kjson.ValidatingPolicy, error) {
+	var policies []*kjson.ValidatingPolicy
+	if spec.Policy != nil {
+		policies = append(policies, spec.Policy)
+	}
+	policies = append(policies, spec.Policies...)
+
+	for _, file := range spec.PolicyFiles {
+		b, err := network.Fetch(file, network.WithBaseDir(workDir))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadPolicy, file, err)
+		}
+		filePolicies, err := parsePolicies(b)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadPolicy, file, err)
+		}
+		policies = append(policies, filePolicies...)
+	}
+
+	names := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		if names[policy.Name] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicatePolicy, policy.Name)
+		}
+		names[policy.Name] = true
+	}
+
+	return policies, nil
+}
+
+// parsePolicies parses the ValidatingPolicy documents of a YAML or JSON file
+func parsePolicies(b []byte) ([]*kjson.ValidatingPolicy, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), 4096)
+
+	var policies []*kjson.ValidatingPolicy
+	for {
+		policy := &kjson.ValidatingPolicy{}
+		if err := decoder.Decode(policy); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		// skip empty documents, e.g. following a trailing ---
+		if policy.Kind == "" && policy.Name == "" && len(policy.Spec.Rules) == 0 {
+			continue
+		}
+		if policy.Kind != validatingPolicyKind {
+			return nil, fmt.Errorf("policy %q has unsupported kind %q", policy.Name, policy.Kind)
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		return nil, errors.New("no policies found")
+	}
+
+	return policies, nil
+}
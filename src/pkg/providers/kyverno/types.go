@@ -2,15 +2,33 @@ package kyverno
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	kjson "github.com/kyverno/kyverno-json/pkg/apis/policy/v1alpha1"
 	"github.com/mike-winberry/lulalib/src/types"
 )
 
+var (
+	ErrLoadPolicy      = errors.New("error loading policy")
+	ErrDuplicatePolicy = errors.New("policy names must be unique")
+)
+
 type KyvernoProvider struct {
 	// Spec is the specification of the Kyverno policy
 	Spec *KyvernoSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// cache is the policies loaded from the spec, which are shared by copies of the provider
+	cache *policyCache
+}
+
+// policyCache holds the policies of a provider, keyed by the working directory its policy files were
+// resolved against. The policy files are loaded once per working directory for the lifetime of the
+// provider, so changes to them are not picked up by later evaluations.
+type policyCache struct {
+	mu       sync.Mutex
+	workDir  string
+	policies []*kjson.ValidatingPolicy
 }
 
 func CreateKyvernoProvider(_ context.Context, spec *KyvernoSpec) (types.Provider, error) {
@@ -19,17 +37,42 @@ func CreateKyvernoProvider(_ context.Context, spec *KyvernoSpec) (types.Provider
 		return nil, fmt.Errorf("spec is nil")
 	}
 
-	if spec.Policy == nil {
+	if spec.Policy == nil && len(spec.Policies) == 0 && len(spec.PolicyFiles) == 0 {
 		return nil, fmt.Errorf("policy is nil")
 	}
 
+	names := make(map[string]bool)
+	if spec.Policy != nil {
+		names[spec.Policy.Name] = true
+	}
+	for _, policy := range spec.Policies {
+		if policy == nil {
+			return nil, fmt.Errorf("policy is nil")
+		}
+		if names[policy.Name] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicatePolicy, policy.Name)
+		}
+		names[policy.Name] = true
+	}
+
+	for _, file := range spec.PolicyFiles {
+		if file == "" {
+			return nil, fmt.Errorf("policy file cannot be empty")
+		}
+	}
+
 	return KyvernoProvider{
-		Spec: spec,
+		Spec:  spec,
+		cache: &policyCache{},
 	}, nil
 }
 
 func (k KyvernoProvider) Evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
-	results, err := GetValidatedAssets(ctx, k.Spec.Policy, resources, k.Spec.Output)
+	policies, err := k.loadedPolicies(ctx)
+	if err != nil {
+		return types.Result{}, err
+	}
+	results, err := GetValidatedAssets(ctx, policies, resources, k.Spec.Output)
 	if err != nil {
 		return types.Result{}, err
 	}
@@ -37,11 +80,18 @@ func (k KyvernoProvider) Evaluate(ctx context.Context, resources types.DomainRes
 }
 
 type KyvernoSpec struct {
-	Policy *kjson.ValidatingPolicy `json:"policy" yaml:"policy"`
-	Output *KyvernoOutput          `json:"output,omitempty" yaml:"output,omitempty"`
+	// Policy is a single policy. At least one of policy, policies, or policy-files is required
+	Policy *kjson.ValidatingPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+	// Policies is a list of policies, which are evaluated with the policy
+	Policies []*kjson.ValidatingPolicy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PolicyFiles is a list of YAML or JSON files of policies, each of which may hold several policy documents
+	PolicyFiles []string       `json:"policy-files,omitempty" yaml:"policy-files,omitempty"`
+	Output      *KyvernoOutput `json:"output,omitempty" yaml:"output,omitempty"`
 }
 
 type KyvernoOutput struct {
-	Validation   string   `json:"validation" yaml:"validation"`
+	// Validation is a comma separated list of the policy/rule pairs that are counted in the result
+	Validation string `json:"validation" yaml:"validation"`
+	// Observations is a list of the policy/rule pairs that are observed
 	Observations []string `json:"observations" yaml:"observations"`
 }
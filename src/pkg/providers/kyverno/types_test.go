@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	kjson "github.com/kyverno/kyverno-json/pkg/apis/policy/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/mike-winberry/lulalib/src/pkg/providers/kyverno"
 )
 
@@ -50,6 +52,35 @@ func TestCreateKyvernoProvider(t *testing.T) {
 			spec:    &kyverno.KyvernoSpec{},
 			wantErr: true,
 		},
+		{
+			name: "valid policies and policy files",
+			spec: &kyverno.KyvernoSpec{
+				Policies: []*kjson.ValidatingPolicy{
+					{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+				},
+				PolicyFiles: []string{"policies.yaml"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "duplicate policy names",
+			spec: &kyverno.KyvernoSpec{
+				Policy:   &kjson.ValidatingPolicy{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+				Policies: []*kjson.ValidatingPolicy{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "nil policy in policies",
+			spec:    &kyverno.KyvernoSpec{Policies: []*kjson.ValidatingPolicy{nil}},
+			wantErr: true,
+		},
+		{
+			name:    "empty policy file",
+			spec:    &kyverno.KyvernoSpec{PolicyFiles: []string{""}},
+			wantErr: true,
+		},
 		{
 			name: "empty policy",
 			spec: &kyverno.KyvernoSpec{
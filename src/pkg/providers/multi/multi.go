@@ -0,0 +1,169 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+const (
+	// CombineAll is satisfied if every provider is satisfied
+	CombineAll = "all"
+	// CombineAny is satisfied if at least one provider is satisfied
+	CombineAny = "any"
+	// CombineThreshold is satisfied if at least threshold providers are satisfied
+	CombineThreshold = "threshold"
+)
+
+// NamedProvider is a constituent provider whose observations are prefixed by name
+type NamedProvider struct {
+	Name     string
+	Provider types.Provider
+}
+
+// MultiProvider evaluates the same resources with several providers and combines their results
+type MultiProvider struct {
+	Providers []NamedProvider
+	// Combine is how the results are combined: all, any, or threshold
+	Combine string
+	// Threshold is the number of providers that must be satisfied, if Combine is threshold
+	Threshold int
+}
+
+func CreateMultiProvider(providers []NamedProvider, combine string, threshold int) (types.Provider, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("some providers must be specified")
+	}
+
+	var errs error
+	names := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		if p.Name == "" {
+			errs = errors.Join(errs, errors.New("provider name cannot be empty"))
+		} else if names[p.Name] {
+			errs = errors.Join(errs, fmt.Errorf("provider name %s must be unique", p.Name))
+		}
+		names[p.Name] = true
+
+		if p.Provider == nil {
+			errs = errors.Join(errs, fmt.Errorf("provider %s is nil", p.Name))
+		}
+	}
+
+	if combine == "" {
+		combine = CombineAll
+	}
+	switch combine {
+	case CombineAll, CombineAny:
+		if threshold != 0 {
+			errs = errors.Join(errs, fmt.Errorf("threshold can only be set with combine %s", CombineThreshold))
+		}
+	case CombineThreshold:
+		if threshold < 1 || threshold > len(providers) {
+			errs = errors.Join(errs, fmt.Errorf("threshold must be between 1 and the number of providers (%d)", len(providers)))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("unsupported combine %s", combine))
+	}
+
+	if errs != nil {
+		return nil, errs
+	}
+	return MultiProvider{Providers: providers, Combine: combine, Threshold: threshold}, nil
+}
+
+// Evaluate evaluates the resources with every provider and combines the results. The passing and
+// failing counts are the sums of those of the providers, except that failing is zero when the
+// combination is satisfied and at least one when it is not. The observations of each provider are
+// prefixed by its name, and the result of each provider is observed under its name. Print output
+// and traces are likewise prefixed by the provider name.
+func (p MultiProvider) Evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
+	result := types.Result{Observations: make(map[string]string)}
+	var errs error
+	satisfied := 0
+	for _, named := range p.Providers {
+		select {
+		case <-ctx.Done():
+			return types.Result{}, fmt.Errorf("canceled: %s", ctx.Err())
+		default:
+		}
+
+		r, err := named.Provider.Evaluate(ctx, resources)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("provider %s: %w", named.Name, err))
+			continue
+		}
+
+		state := "not-satisfied"
+		if r.Passing > 0 && r.Failing <= 0 {
+			state = "satisfied"
+			satisfied++
+		}
+		result.Passing += r.Passing
+		result.Failing += r.Failing
+		result.Observations[named.Name] = fmt.Sprintf("%s (%d passing, %d failing)", state, r.Passing, r.Failing)
+		for key, value := range r.Observations {
+			result.Observations[fmt.Sprintf("%s: %s", named.Name, key)] = value
+		}
+		for key, value := range r.ObservationData {
+			if result.ObservationData == nil {
+				result.ObservationData = make(map[string]interface{})
+			}
+			result.ObservationData[fmt.Sprintf("%s: %s", named.Name, key)] = value
+		}
+		for _, msg := range r.Prints {
+			result.Prints = append(result.Prints, fmt.Sprintf("%s: %s", named.Name, msg))
+		}
+		if r.Trace != "" {
+			result.Trace += fmt.Sprintf("provider %s:\n%s", named.Name, r.Trace)
+		}
+	}
+	if errs != nil {
+		return types.Result{}, errs
+	}
+
+	if p.isSatisfied(satisfied) {
+		result.Failing = 0
+	} else if result.Failing <= 0 {
+		result.Failing = 1
+	}
+	return result, nil
+}
+
+// RunProviderTests runs the tests of every provider that has tests of its own, and adds their results to
+// the report with the test names prefixed by the provider name. The coverage of each provider is reported
+// under its name.
+func (p MultiProvider) RunProviderTests(ctx context.Context, report *types.LulaValidationTestReport) {
+	for _, named := range p.Providers {
+		provider, ok := named.Provider.(types.TestableProvider)
+		if !ok {
+			continue
+		}
+		providerReport := types.NewLulaValidationTestReport(named.Name)
+		provider.RunProviderTests(ctx, providerReport)
+		for _, result := range providerReport.TestResults {
+			result.TestName = fmt.Sprintf("%s: %s", named.Name, result.TestName)
+			report.AddTestResult(result)
+		}
+		if providerReport.Coverage != nil {
+			if report.ProviderCoverage == nil {
+				report.ProviderCoverage = make(map[string]float64)
+			}
+			report.ProviderCoverage[named.Name] = *providerReport.Coverage
+		}
+	}
+}
+
+// isSatisfied returns true if the number of satisfied providers satisfies the combination
+func (p MultiProvider) isSatisfied(satisfied int) bool {
+	switch p.Combine {
+	case CombineAny:
+		return satisfied >= 1
+	case CombineThreshold:
+		return satisfied >= p.Threshold
+	default:
+		return satisfied == len(p.Providers)
+	}
+}
@@ -0,0 +1,210 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+var _ types.TestableProvider = (*MultiProvider)(nil)
+
+type stubProvider struct {
+	result types.Result
+	err    error
+}
+
+func (s stubProvider) Evaluate(_ context.Context, _ types.DomainResources) (types.Result, error) {
+	return s.result, s.err
+}
+
+type testableProvider struct {
+	stubProvider
+	results  []*types.LulaValidationTestResult
+	coverage *float64
+}
+
+func (s testableProvider) RunProviderTests(_ context.Context, report *types.LulaValidationTestReport) {
+	for _, result := range s.results {
+		report.AddTestResult(result)
+	}
+	report.Coverage = s.coverage
+}
+
+var (
+	passing = stubProvider{result: types.Result{
+		Passing:         2,
+		Observations:    map[string]string{"validate.msg": "all pods labeled", "validate.pods": "- a"},
+		ObservationData: map[string]interface{}{"validate.pods": []interface{}{"a"}},
+		Prints:          []string{"validate.rego:4: a"},
+	}}
+	failing = stubProvider{result: types.Result{Passing: 1, Failing: 1, Observations: map[string]string{"validate.msg": "pod a unlabeled"}}}
+	empty   = stubProvider{result: types.Result{}}
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		providers   []NamedProvider
+		combine     string
+		threshold   int
+		wantPassing int
+		wantFailing int
+	}{
+		{
+			name:        "all satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: passing}},
+			wantPassing: 4,
+		},
+		{
+			name:        "all not satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: failing}},
+			combine:     CombineAll,
+			wantPassing: 3,
+			wantFailing: 1,
+		},
+		{
+			name:        "all with a provider without results",
+			providers:   []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: empty}},
+			wantPassing: 2,
+			wantFailing: 1,
+		},
+		{
+			name:        "any satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: failing}, {Name: "b", Provider: passing}},
+			combine:     CombineAny,
+			wantPassing: 3,
+		},
+		{
+			name:        "any not satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: failing}, {Name: "b", Provider: empty}},
+			combine:     CombineAny,
+			wantPassing: 1,
+			wantFailing: 1,
+		},
+		{
+			name:        "threshold satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: failing}, {Name: "c", Provider: passing}},
+			combine:     CombineThreshold,
+			threshold:   2,
+			wantPassing: 5,
+		},
+		{
+			name:        "threshold not satisfied",
+			providers:   []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: failing}, {Name: "c", Provider: failing}},
+			combine:     CombineThreshold,
+			threshold:   2,
+			wantPassing: 4,
+			wantFailing: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := CreateMultiProvider(tt.providers, tt.combine, tt.threshold)
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(context.Background(), types.DomainResources{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPassing, result.Passing)
+			require.Equal(t, tt.wantFailing, result.Failing)
+		})
+	}
+}
+
+func TestEvaluateObservations(t *testing.T) {
+	t.Parallel()
+
+	provider, err := CreateMultiProvider([]NamedProvider{
+		{Name: "rego", Provider: passing},
+		{Name: "kyverno", Provider: failing},
+	}, CombineAny, 0)
+	require.NoError(t, err)
+
+	result, err := provider.Evaluate(context.Background(), types.DomainResources{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"rego":                  "satisfied (2 passing, 0 failing)",
+		"rego: validate.msg":    "all pods labeled",
+		"rego: validate.pods":   "- a",
+		"kyverno":               "not-satisfied (1 passing, 1 failing)",
+		"kyverno: validate.msg": "pod a unlabeled",
+	}, result.Observations)
+	require.Equal(t, map[string]interface{}{"rego: validate.pods": []interface{}{"a"}}, result.ObservationData)
+	require.Equal(t, []string{"rego: validate.rego:4: a"}, result.Prints)
+}
+
+func TestEvaluateError(t *testing.T) {
+	t.Parallel()
+
+	provider, err := CreateMultiProvider([]NamedProvider{
+		{Name: "a", Provider: passing},
+		{Name: "b", Provider: stubProvider{err: errors.New("invalid policy")}},
+	}, CombineAny, 0)
+	require.NoError(t, err)
+
+	_, err = provider.Evaluate(context.Background(), types.DomainResources{})
+	require.EqualError(t, err, "provider b: invalid policy")
+}
+
+func TestRunProviderTests(t *testing.T) {
+	t.Parallel()
+
+	full, partial := 100.0, 50.0
+	provider, err := CreateMultiProvider([]NamedProvider{
+		{Name: "rego", Provider: testableProvider{stubProvider: passing, coverage: &full, results: []*types.LulaValidationTestResult{
+			{TestName: "data.validate_test.test_labeled", Pass: true, Result: "pass"},
+		}}},
+		{Name: "kyverno", Provider: failing},
+		{Name: "annotations", Provider: testableProvider{stubProvider: passing, coverage: &partial, results: []*types.LulaValidationTestResult{
+			{TestName: "data.validate_test.test_annotated", Pass: false, Result: "fail"},
+		}}},
+	}, CombineAny, 0)
+	require.NoError(t, err)
+
+	report := types.NewLulaValidationTestReport("multi")
+	provider.(types.TestableProvider).RunProviderTests(context.Background(), report)
+	require.Equal(t, []*types.LulaValidationTestResult{
+		{TestName: "rego: data.validate_test.test_labeled", Pass: true, Result: "pass"},
+		{TestName: "annotations: data.validate_test.test_annotated", Pass: false, Result: "fail"},
+	}, report.TestResults)
+	require.Nil(t, report.Coverage)
+	require.Equal(t, map[string]float64{"rego": 100, "annotations": 50}, report.ProviderCoverage)
+}
+
+func TestCreateMultiProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		providers []NamedProvider
+		combine   string
+		threshold int
+		wantErr   bool
+	}{
+		{name: "valid", providers: []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: passing}}},
+		{name: "valid threshold", providers: []NamedProvider{{Name: "a", Provider: passing}, {Name: "b", Provider: passing}}, combine: CombineThreshold, threshold: 2},
+		{name: "no providers", providers: nil, wantErr: true},
+		{name: "missing name", providers: []NamedProvider{{Provider: passing}}, wantErr: true},
+		{name: "duplicate name", providers: []NamedProvider{{Name: "a", Provider: passing}, {Name: "a", Provider: passing}}, wantErr: true},
+		{name: "nil provider", providers: []NamedProvider{{Name: "a"}}, wantErr: true},
+		{name: "unsupported combine", providers: []NamedProvider{{Name: "a", Provider: passing}}, combine: "most", wantErr: true},
+		{name: "threshold without combine threshold", providers: []NamedProvider{{Name: "a", Provider: passing}}, combine: CombineAny, threshold: 1, wantErr: true},
+		{name: "threshold too low", providers: []NamedProvider{{Name: "a", Provider: passing}}, combine: CombineThreshold, wantErr: true},
+		{name: "threshold too high", providers: []NamedProvider{{Name: "a", Provider: passing}}, combine: CombineThreshold, threshold: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateMultiProvider(tt.providers, tt.combine, tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateMultiProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/util"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// loadData fetches the data documents specified in the dataPaths map and returns a single data
+// document with each loaded under its path, e.g. lula.exemptions is loaded as data.lula.exemptions.
+func loadData(ctx context.Context, dataPaths map[string]string) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	if len(dataPaths) == 0 {
+		return data, nil
+	}
+
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	paths := make([]string, 0, len(dataPaths))
+	for path := range dataPaths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		b, err := network.Fetch(dataPaths[path], network.WithBaseDir(workDir))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadData, path, err)
+		}
+		// JSON is a subset of YAML, so both are accepted
+		var document interface{}
+		if err := util.Unmarshal(b, &document); err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadData, path, err)
+		}
+		if err := insertData(data, strings.Split(path, "."), document); err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadData, path, err)
+		}
+	}
+
+	return data, nil
+}
+
+// loadBundles reads the OPA bundles, each a directory or a tar.gz file, and adds their modules to
+// modules and their data to data
+func loadBundles(ctx context.Context, bundles []string, modules map[string]string, data map[string]interface{}) error {
+	if len(bundles) == 0 {
+		return nil
+	}
+
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	for _, src := range bundles {
+		b, err := readBundle(src, workDir)
+		if err != nil {
+			return fmt.Errorf("%w %s: %w", ErrLoadBundle, src, err)
+		}
+
+		for _, module := range b.Modules {
+			name := fmt.Sprintf("%s/%s", src, strings.TrimPrefix(module.Path, "/"))
+			if _, ok := modules[name]; ok {
+				return fmt.Errorf("%w %s: module %s is already loaded", ErrLoadBundle, src, name)
+			}
+			modules[name] = string(module.Raw)
+		}
+		if err := mergeData(data, b.Data, nil); err != nil {
+			return fmt.Errorf("%w %s: %w", ErrLoadBundle, src, err)
+		}
+	}
+
+	return nil
+}
+
+// readBundle reads a bundle from a local directory, or fetches it as a tar.gz file
+func readBundle(src, workDir string) (bundle.Bundle, error) {
+	if network.IsFileLocal(src) && !strings.Contains(src, "@") {
+		path := strings.TrimPrefix(strings.TrimPrefix(src, "file://"), "file:")
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return bundle.NewCustomReader(bundle.NewDirectoryLoader(path)).Read()
+		}
+	}
+
+	b, err := network.Fetch(src, network.WithBaseDir(workDir))
+	if err != nil {
+		return bundle.Bundle{}, err
+	}
+	return bundle.NewCustomReader(bundle.NewTarballLoaderWithBaseURL(bytes.NewReader(b), src)).Read()
+}
+
+// insertData inserts the value into the data document at the path, merging it with any object
+// already at the path
+func insertData(data map[string]interface{}, path []string, value interface{}) error {
+	for i, key := range path[:len(path)-1] {
+		next, ok := data[key]
+		if !ok {
+			next = make(map[string]interface{})
+			data[key] = next
+		}
+		object, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		data = object
+	}
+	return mergeData(data, map[string]interface{}{path[len(path)-1]: value}, path[:len(path)-1])
+}
+
+// mergeData merges the src object into the dst object. Objects at the same path are merged, and
+// any other values at the same path are a conflict.
+func mergeData(dst, src map[string]interface{}, path []string) error {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		keyPath := append(append([]string{}, path...), key)
+		existingObject, isObject := existing.(map[string]interface{})
+		valueObject, valueIsObject := value.(map[string]interface{})
+		if !isObject || !valueIsObject {
+			return fmt.Errorf("conflicting data at %s", strings.Join(keyPath, "."))
+		}
+		if err := mergeData(existingObject, valueObject, keyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,29 +5,98 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/mike-winberry/lulalib/src/pkg/message"
 	"github.com/mike-winberry/lulalib/src/types"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/topdown/print"
 )
 
 var (
 	ErrCompileRego  = errors.New("failed to compile rego policy")
 	ErrEvaluateRego = errors.New("failed to evaluate rego policy")
+
+	errNoResources = errors.New("opa validation not performed - no resources to validate")
 )
 
 // mainPolicyModuleName is the name of the OPA module containing the main policy from the spec.rego field.
 const mainPolicyModuleName = "validate.rego"
 
-// GetValidatedAssets performs the validation of the dataset against the given rego policy
-func GetValidatedAssets(ctx context.Context, regoPolicy string, regoModules map[string]string, dataset map[string]interface{}, output *OpaOutput) (types.Result, error) {
-	var matchResult types.Result
+// RestrictedBuiltins are the built-in functions that can reach the network or the environment lula is
+// running in. Policies that call them fail to compile unless they are allowed by types.LulaValidationAllowedBuiltins.
+var RestrictedBuiltins = []string{
+	"http.send",
+	"net.lookup_ip_addr",
+	"opa.runtime",
+}
 
+// unsafeBuiltins returns the restricted built-in functions that are not allowed by the context
+func unsafeBuiltins(ctx context.Context) map[string]struct{} {
+	allowed, _ := ctx.Value(types.LulaValidationAllowedBuiltins).([]string)
+	unsafe := make(map[string]struct{}, len(RestrictedBuiltins))
+	for _, name := range RestrictedBuiltins {
+		if !slices.Contains(allowed, name) {
+			unsafe[name] = struct{}{}
+		}
+	}
+	return unsafe
+}
+
+// GetValidatedAssets performs the validation of the dataset against the given rego policy, with the
+// data document available to the policy as data
+func GetValidatedAssets(ctx context.Context, regoPolicy string, regoModules map[string]string, data map[string]interface{}, dataset map[string]interface{}, output *OpaOutput) (types.Result, error) {
 	if len(dataset) == 0 {
-		return matchResult, errors.New("opa validation not performed - no resources to validate")
+		return types.Result{}, errNoResources
 	}
 
+	policy, err := preparePolicy(ctx, regoPolicy, regoModules, data, output)
+	if err != nil {
+		return types.Result{}, err
+	}
+	return policy.evaluate(ctx, dataset)
+}
+
+// parseModules parses the modules, keyed by their names
+func parseModules(modules map[string]string) (map[string]*ast.Module, error) {
+	parsed := make(map[string]*ast.Module, len(modules))
+	for name, module := range modules {
+		m, err := ast.ParseModule(name, module)
+		if err != nil {
+			message.Debugf("failed to parse rego policy: %s", err.Error())
+			return nil, fmt.Errorf("%w: %w", ErrCompileRego, err)
+		}
+		parsed[name] = m
+	}
+	return parsed, nil
+}
+
+// newCompiler returns a compiler that rejects the unsafe built-ins, before anything is evaluated.
+// Print statements are kept so their output can be captured when debugging, and are no-ops otherwise.
+func newCompiler(unsafe map[string]struct{}) *ast.Compiler {
+	return ast.NewCompiler().
+		WithEnablePrintStatements(true).
+		WithUnsafeBuiltins(unsafe)
+}
+
+// preparedPolicy is a compiled policy with its queries prepared for evaluation, which can be
+// evaluated against any number of datasets
+type preparedPolicy struct {
+	output *OpaOutput
+	// validation is the query of the validation decision, unless violations are counted
+	validation rego.PreparedEvalQuery
+	violations rego.PreparedEvalQuery
+	passing    *rego.PreparedEvalQuery
+	// observations are the queries of the observations, in the order of the output
+	observations []rego.PreparedEvalQuery
+}
+
+// preparePolicy compiles the policy with its modules and prepares the queries of the output
+func preparePolicy(ctx context.Context, regoPolicy string, regoModules map[string]string, data map[string]interface{}, output *OpaOutput) (*preparedPolicy, error) {
 	if output == nil {
 		output = &OpaOutput{}
 	}
@@ -38,68 +107,193 @@ func GetValidatedAssets(ctx context.Context, regoPolicy string, regoModules map[
 	}
 	modules[mainPolicyModuleName] = regoPolicy
 
-	compiler, err := ast.CompileModules(modules)
+	parsed, err := parseModules(modules)
 	if err != nil {
-		message.Debugf("failed to compile rego policy: %s", err.Error())
-		return matchResult, fmt.Errorf("%w: %w", ErrCompileRego, err)
+		return nil, err
 	}
-
-	// Get validation decision
-	validation := "validate.validate"
-	if output.Validation != "" {
-		validation = output.Validation
+	unsafe := unsafeBuiltins(ctx)
+	compiler := newCompiler(unsafe)
+	compiler.Compile(parsed)
+	if compiler.Failed() {
+		message.Debugf("failed to compile rego policy: %s", compiler.Errors.Error())
+		return nil, fmt.Errorf("%w: %w", ErrCompileRego, compiler.Errors)
 	}
+	store := inmem.NewFromObject(data)
 
-	regoCalcValid := rego.New(
-		rego.Query(fmt.Sprintf("data.%s", validation)),
-		rego.Compiler(compiler),
-		rego.Input(dataset),
-	)
-
-	resultValid, err := regoCalcValid.Eval(ctx)
-	if err != nil {
-		return matchResult, fmt.Errorf("%w: %w", ErrEvaluateRego, err)
+	prepare := func(path string) (rego.PreparedEvalQuery, error) {
+		query, err := rego.New(
+			rego.Query(fmt.Sprintf("data.%s", path)),
+			rego.Compiler(compiler),
+			rego.Store(store),
+			rego.EnablePrintStatements(true),
+			rego.UnsafeBuiltins(unsafe),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return query, fmt.Errorf("%w: %w", ErrEvaluateRego, err)
+		}
+		return query, nil
 	}
-	// Checking result length is non-zero: will be zero if validation returns false
-	if len(resultValid) != 0 {
-		// Extra check on validation value = true, to ensure it's a boolean return since it could be anything
-		if matched, ok := resultValid[0].Expressions[0].Value.(bool); ok && matched {
-			matchResult.Passing += 1
-		} else {
-			matchResult.Failing += 1
-			if !ok {
-				message.Debugf("Validation field expected bool and got %s", reflect.TypeOf(resultValid[0].Expressions[0].Value))
+
+	policy := &preparedPolicy{output: output}
+	if output.Violations != "" {
+		if policy.violations, err = prepare(output.Violations); err != nil {
+			return nil, err
+		}
+		if output.Passing != "" {
+			passing, err := prepare(output.Passing)
+			if err != nil {
+				return nil, err
 			}
+			policy.passing = &passing
 		}
 	} else {
-		matchResult.Failing += 1
+		// Get validation decision
+		validation := "validate.validate"
+		if output.Validation != "" {
+			validation = output.Validation
+		}
+		if policy.validation, err = prepare(validation); err != nil {
+			return nil, err
+		}
 	}
 
-	// Get additional observations, if they exist - only supports string output
-	observations := make(map[string]string)
 	for _, obv := range output.Observations {
-		regoCalcObv := rego.New(
-			rego.Query(fmt.Sprintf("data.%s", obv)),
-			rego.Compiler(compiler),
-			rego.Input(dataset),
-		)
+		query, err := prepare(obv)
+		if err != nil {
+			return nil, err
+		}
+		policy.observations = append(policy.observations, query)
+	}
+
+	return policy, nil
+}
+
+// evaluate evaluates the prepared queries against the dataset. The output of print statements is
+// captured when debugging, and the trace of the validation decision when tracing.
+func (p *preparedPolicy) evaluate(ctx context.Context, dataset map[string]interface{}) (types.Result, error) {
+	var matchResult types.Result
+
+	opts := []rego.EvalOption{rego.EvalInput(dataset)}
+	trace, _ := ctx.Value(types.LulaValidationTrace).(bool)
+	var prints *printCollector
+	if trace || message.GetLogLevel() >= message.DebugLevel {
+		prints = &printCollector{}
+		opts = append(opts, rego.EvalPrintHook(prints))
+	}
+	decisionOpts := opts
+	var tracer *topdown.BufferTracer
+	if trace {
+		tracer = topdown.NewBufferTracer()
+		decisionOpts = append(append([]rego.EvalOption{}, opts...), rego.EvalQueryTracer(tracer))
+	}
 
-		resultObv, err := regoCalcObv.Eval(ctx)
+	if p.output.Violations != "" {
+		if err := p.countViolations(ctx, &matchResult, decisionOpts); err != nil {
+			return matchResult, err
+		}
+	} else {
+		resultValid, err := p.validation.Eval(ctx, decisionOpts...)
 		if err != nil {
 			return matchResult, fmt.Errorf("%w: %w", ErrEvaluateRego, err)
 		}
-		// To do: check if resultObv is empty - basically some extra error handling if a user defines an output but it's not coming out of the rego
-		if len(resultObv) != 0 {
-			if matched, ok := resultObv[0].Expressions[0].Value.(string); ok {
-				observations[obv] = matched
+		// Checking result length is non-zero: will be zero if validation returns false
+		if len(resultValid) != 0 {
+			// Extra check on validation value = true, to ensure it's a boolean return since it could be anything
+			if matched, ok := resultValid[0].Expressions[0].Value.(bool); ok && matched {
+				matchResult.Passing += 1
 			} else {
-				message.Debugf("Observation field %s expected string and got %s", obv, reflect.TypeOf(resultObv[0].Expressions[0].Value))
+				matchResult.Failing += 1
+				if !ok {
+					message.Debugf("Validation field expected bool and got %s", reflect.TypeOf(resultValid[0].Expressions[0].Value))
+				}
 			}
+		} else {
+			matchResult.Failing += 1
+		}
+	}
+
+	// Get additional observations, if they exist - values other than strings are kept as structured data
+	for i, obv := range p.output.Observations {
+		resultObv, err := p.observations[i].Eval(ctx, opts...)
+		if err != nil {
+			return matchResult, fmt.Errorf("%w: %w", ErrEvaluateRego, err)
+		}
+		// To do: check if resultObv is empty - basically some extra error handling if a user defines an output but it's not coming out of the rego
+		if len(resultObv) != 0 {
+			matchResult.AddObservation(obv, resultObv[0].Expressions[0].Value)
 		} else {
 			message.Debugf("Observation field %s not output from rego", obv)
 		}
 	}
-	matchResult.Observations = observations
+	if matchResult.Observations == nil {
+		matchResult.Observations = make(map[string]string)
+	}
+
+	if prints != nil {
+		matchResult.Prints = prints.prints
+	}
+	if tracer != nil {
+		var sb strings.Builder
+		topdown.PrettyTraceWithLocation(&sb, *tracer)
+		matchResult.Trace = sb.String()
+	}
 
 	return matchResult, nil
 }
+
+// countViolations adds a failing result and an observation for each violation, and a passing result for each
+// passing item. If there are no violations and no passing rule, the result is passing.
+func (p *preparedPolicy) countViolations(ctx context.Context, matchResult *types.Result, opts []rego.EvalOption) error {
+	violations, err := evalItems(ctx, p.violations, p.output.Violations, opts)
+	if err != nil {
+		return err
+	}
+	matchResult.Failing += len(violations)
+	for i, violation := range violations {
+		matchResult.AddObservation(types.ItemKey(p.output.Violations, i, len(violations)), violation)
+	}
+
+	if p.passing == nil {
+		if len(violations) == 0 {
+			matchResult.Passing += 1
+		}
+		return nil
+	}
+	passing, err := evalItems(ctx, *p.passing, p.output.Passing, opts)
+	if err != nil {
+		return err
+	}
+	matchResult.Passing += len(passing)
+	return nil
+}
+
+// evalItems returns the elements of the set or array at the path, which are none if it is undefined
+func evalItems(ctx context.Context, query rego.PreparedEvalQuery, path string, opts []rego.EvalOption) ([]interface{}, error) {
+	result, err := query.Eval(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEvaluateRego, err)
+	}
+	if len(result) == 0 {
+		message.Debugf("Field %s not output from rego", path)
+		return nil, nil
+	}
+	// sets are returned as arrays
+	items, ok := result[0].Expressions[0].Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s expected a set or array and got %s", ErrEvaluateRego, path, reflect.TypeOf(result[0].Expressions[0].Value))
+	}
+	return items, nil
+}
+
+// printCollector is a print hook that collects the output of print statements, prefixed by their location
+type printCollector struct {
+	prints []string
+}
+
+func (c *printCollector) Print(pctx print.Context, msg string) error {
+	if pctx.Location != nil {
+		msg = fmt.Sprintf("%s:%d: %s", pctx.Location.File, pctx.Location.Row, msg)
+	}
+	c.prints = append(c.prints, msg)
+	return nil
+}
@@ -1,11 +1,22 @@
 package opa_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/stretchr/testify/require"
+
 	"github.com/mike-winberry/lulalib/src/pkg/providers/opa"
+	"github.com/mike-winberry/lulalib/src/types"
 )
 
 func TestOpaModules(t *testing.T) {
@@ -86,6 +97,450 @@ func TestOpaModules(t *testing.T) {
 	}
 }
 
+func TestStructuredObservations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	provider, err := opa.CreateOpaProvider(ctx, &opa.OpaSpec{
+		Rego: `package validate
+
+default validate = false
+
+violations[msg] {
+	some name
+	input.pods[name].privileged
+	msg := sprintf("pod %s is privileged", [name])
+}
+
+count_privileged := count(violations)
+
+summary := {"checked": count(input.pods), "violations": violations}
+
+msg := "pods checked"`,
+		Output: &opa.OpaOutput{
+			Observations: []string{"validate.violations", "validate.count_privileged", "validate.summary", "validate.msg"},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := provider.Evaluate(ctx, map[string]interface{}{
+		"pods": map[string]interface{}{
+			"a": map[string]interface{}{"privileged": true},
+			"b": map[string]interface{}{"privileged": true},
+			"c": map[string]interface{}{"privileged": false},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"validate.violations":       "- pod a is privileged\n- pod b is privileged",
+		"validate.count_privileged": "2",
+		"validate.summary":          "checked: 3\nviolations:\n- pod a is privileged\n- pod b is privileged",
+		"validate.msg":              "pods checked",
+	}, result.Observations)
+
+	data, err := json.Marshal(result.ObservationData)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"validate.violations": ["pod a is privileged", "pod b is privileged"],
+		"validate.count_privileged": 2,
+		"validate.summary": {"checked": 3, "violations": ["pod a is privileged", "pod b is privileged"]}
+	}`, string(data))
+}
+
+func TestViolations(t *testing.T) {
+	t.Parallel()
+
+	rego := `package validate
+
+deny[violation] {
+	some name
+	input.pods[name].privileged
+	violation := {"msg": "pod is privileged", "resource": name}
+}
+
+compliant[name] {
+	pod := input.pods[name]
+	not pod.privileged
+}
+
+not_a_set := "pods"`
+
+	pods := map[string]interface{}{
+		"pods": map[string]interface{}{
+			"a": map[string]interface{}{"privileged": true},
+			"b": map[string]interface{}{"privileged": false},
+			"c": map[string]interface{}{"privileged": true},
+			"d": map[string]interface{}{},
+		},
+	}
+	compliantPods := map[string]interface{}{
+		"pods": map[string]interface{}{
+			"a": map[string]interface{}{"privileged": false},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		output           *opa.OpaOutput
+		resources        map[string]interface{}
+		wantErr          error
+		wantPassing      int
+		wantFailing      int
+		wantObservations map[string]string
+	}{
+		{
+			name:        "violations and passing",
+			output:      &opa.OpaOutput{Violations: "validate.deny", Passing: "validate.compliant"},
+			resources:   pods,
+			wantPassing: 2,
+			wantFailing: 2,
+			wantObservations: map[string]string{
+				"validate.deny[0]": "msg: pod is privileged\nresource: a",
+				"validate.deny[1]": "msg: pod is privileged\nresource: c",
+			},
+		},
+		{
+			name:             "no violations without passing",
+			output:           &opa.OpaOutput{Violations: "validate.deny"},
+			resources:        compliantPods,
+			wantPassing:      1,
+			wantObservations: map[string]string{},
+		},
+		{
+			name:             "undefined violations",
+			output:           &opa.OpaOutput{Violations: "validate.undefined", Passing: "validate.compliant"},
+			resources:        compliantPods,
+			wantPassing:      1,
+			wantObservations: map[string]string{},
+		},
+		{
+			name:      "violations not a set",
+			output:    &opa.OpaOutput{Violations: "validate.not_a_set"},
+			resources: pods,
+			wantErr:   opa.ErrEvaluateRego,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider, err := opa.CreateOpaProvider(ctx, &opa.OpaSpec{Rego: rego, Output: tt.output})
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(ctx, tt.resources)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			require.Equal(t, tt.wantPassing, result.Passing)
+			require.Equal(t, tt.wantFailing, result.Failing)
+			require.Equal(t, tt.wantObservations, result.Observations)
+		})
+	}
+}
+
+func TestDataAndBundles(t *testing.T) {
+	t.Parallel()
+
+	// write the bundle in testdata as a tarball
+	b, err := bundle.NewCustomReader(bundle.NewDirectoryLoader("testdata/bundle")).Read()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, bundle.NewWriter(&buf).Write(b))
+	tarball := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(tarball, buf.Bytes(), 0600))
+	checksum := sha256.Sum256(buf.Bytes())
+
+	rego := `package validate
+
+import data.lula.images
+
+default validate = false
+
+validate {
+	not exempt
+	images.allowed(input.pod.image)
+}
+
+exempt {
+	input.pod.name == data.lula.exemptions.pods[_].name
+}`
+	pod := map[string]interface{}{
+		"pod": map[string]interface{}{"name": "app", "image": "registry.example.com/app:1.0.0"},
+	}
+	debugPod := map[string]interface{}{
+		"pod": map[string]interface{}{"name": "debug", "image": "registry.example.com/debug:1.0.0"},
+	}
+
+	tests := []struct {
+		name        string
+		spec        *opa.OpaSpec
+		resources   map[string]interface{}
+		wantErr     error
+		wantPassing int
+	}{
+		{
+			name:        "directory bundle",
+			spec:        &opa.OpaSpec{Rego: rego, Bundles: []string{"bundle"}},
+			resources:   pod,
+			wantPassing: 1,
+		},
+		{
+			name:        "tarball bundle with checksum",
+			spec:        &opa.OpaSpec{Rego: rego, Bundles: []string{fmt.Sprintf("%s@%x", tarball, checksum)}},
+			resources:   pod,
+			wantPassing: 1,
+		},
+		{
+			name:      "tarball bundle with invalid checksum",
+			spec:      &opa.OpaSpec{Rego: rego, Bundles: []string{fmt.Sprintf("%s@%064x", tarball, 0)}},
+			resources: pod,
+			wantErr:   opa.ErrLoadBundle,
+		},
+		{
+			name:      "data exempts pod",
+			spec:      &opa.OpaSpec{Rego: rego, Bundles: []string{"bundle"}, Data: map[string]string{"lula.exemptions": "exemptions.yaml"}},
+			resources: debugPod,
+		},
+		{
+			name:      "missing data",
+			spec:      &opa.OpaSpec{Rego: rego, Bundles: []string{"bundle"}, Data: map[string]string{"lula.exemptions": "missing.yaml"}},
+			resources: pod,
+			wantErr:   opa.ErrLoadData,
+		},
+		{
+			name:      "data conflicting with bundle",
+			spec:      &opa.OpaSpec{Rego: rego, Bundles: []string{"bundle"}, Data: map[string]string{"lula.images.registries": "exemptions.yaml"}},
+			resources: pod,
+			wantErr:   opa.ErrLoadBundle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+			provider, err := opa.CreateOpaProvider(ctx, tt.spec)
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(ctx, tt.resources)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantPassing, result.Passing)
+		})
+	}
+}
+
+func TestPolicyCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	module, err := os.ReadFile("testdata/lula.rego")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lula.rego"), module, 0600))
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+	spec := &opa.OpaSpec{
+		Rego:    "package validate\n\nimport data.lula.labels as lula_labels\n\nvalidate { lula_labels.has_lula_label(input.pod) }",
+		Modules: map[string]string{"lula.labels": "lula.rego"},
+	}
+	provider, err := opa.CreateOpaProvider(ctx, spec)
+	require.NoError(t, err)
+
+	result, err := provider.Evaluate(ctx, dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+
+	// the policy is reused while the module is unchanged
+	for range 3 {
+		result, err = provider.Evaluate(ctx, dummyPod)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Passing)
+	}
+
+	// changing the module prepares the policy again
+	changed := strings.Replace(string(module), `"true"`, `"false"`, 1)
+	require.NotEqual(t, string(module), changed)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lula.rego"), []byte(changed), 0600))
+	result, err = provider.Evaluate(ctx, dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Failing)
+
+	// the module is loaded on every evaluation
+	require.NoError(t, os.Remove(filepath.Join(dir, "lula.rego")))
+	_, err = provider.Evaluate(ctx, dummyPod)
+	require.ErrorIs(t, err, opa.ErrDownloadModule)
+
+	// changing the spec prepares the policy again
+	spec.Modules = nil
+	spec.Rego = "package validate\n\ndefault validate = false"
+	result, err = provider.Evaluate(ctx, dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Failing)
+
+	// a policy that fails to load is not cached
+	spec.Modules = map[string]string{"lula.labels": "lula.rego"}
+	_, err = provider.Evaluate(ctx, dummyPod)
+	require.ErrorIs(t, err, opa.ErrDownloadModule)
+	_, err = provider.Evaluate(ctx, dummyPod)
+	require.ErrorIs(t, err, opa.ErrDownloadModule)
+}
+
+func TestPrintAndTrace(t *testing.T) {
+	t.Parallel()
+
+	spec := &opa.OpaSpec{
+		Rego: "package validate\n\nvalidate {\n  print(\"labels\", input.pod.metadata.labels)\n  input.pod.metadata.labels.lula == \"true\"\n}",
+	}
+	provider, err := opa.CreateOpaProvider(context.Background(), spec)
+	require.NoError(t, err)
+
+	// print statements are no-ops unless tracing, or debugging
+	result, err := provider.Evaluate(context.Background(), dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+	require.Empty(t, result.Prints)
+	require.Empty(t, result.Trace)
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationTrace, true)
+	result, err = provider.Evaluate(ctx, dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+	require.Equal(t, []string{`validate.rego:4: labels {"lula": "true"}`}, result.Prints)
+	require.Contains(t, result.Trace, "Enter data.validate.validate")
+	require.Contains(t, result.Trace, "validate.rego:5")
+}
+
+func TestRestrictedBuiltins(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rego    string
+		allowed []string
+		wantErr bool
+	}{
+		{
+			name:    "http.send is denied",
+			rego:    "package validate\n\nvalidate { http.send({\"method\": \"get\", \"url\": \"https://example.com\"}).status_code == 200 }",
+			wantErr: true,
+		},
+		{
+			name:    "net.lookup_ip_addr is denied",
+			rego:    "package validate\n\nvalidate { count(net.lookup_ip_addr(\"example.com\")) > 0 }",
+			wantErr: true,
+		},
+		{
+			name:    "opa.runtime is denied",
+			rego:    "package validate\n\nvalidate { opa.runtime().env.HOME }",
+			wantErr: true,
+		},
+		{
+			name:    "opa.runtime is denied in a rule that is not the validation",
+			rego:    "package validate\n\nvalidate { true }\n\nenv := opa.runtime().env",
+			wantErr: true,
+		},
+		{
+			name:    "opa.runtime is allowed",
+			rego:    "package validate\n\nvalidate { is_object(opa.runtime()) }",
+			allowed: []string{"opa.runtime"},
+		},
+		{
+			name:    "allowing another built-in does not allow opa.runtime",
+			rego:    "package validate\n\nvalidate { is_object(opa.runtime()) }",
+			allowed: []string{"http.send"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.WithValue(context.Background(), types.LulaValidationAllowedBuiltins, tt.allowed)
+			provider, err := opa.CreateOpaProvider(ctx, &opa.OpaSpec{Rego: tt.rego})
+			require.NoError(t, err)
+
+			result, err := provider.Evaluate(ctx, dummyPod)
+			if tt.wantErr {
+				require.ErrorIs(t, err, opa.ErrCompileRego)
+				require.ErrorContains(t, err, "unsafe built-in function")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, result.Passing)
+		})
+	}
+}
+
+func TestRestrictedBuiltinsCache(t *testing.T) {
+	t.Parallel()
+
+	provider, err := opa.CreateOpaProvider(context.Background(), &opa.OpaSpec{
+		Rego: "package validate\n\nvalidate { is_object(opa.runtime()) }",
+	})
+	require.NoError(t, err)
+
+	allowed := context.WithValue(context.Background(), types.LulaValidationAllowedBuiltins, []string{"opa.runtime"})
+	result, err := provider.Evaluate(allowed, dummyPod)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Passing)
+
+	// a policy prepared while a built-in was allowed is not reused once it is denied
+	_, err = provider.Evaluate(context.Background(), dummyPod)
+	require.ErrorIs(t, err, opa.ErrCompileRego)
+}
+
+func TestRunProviderTests(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, "testdata")
+	provider, err := opa.CreateOpaProvider(ctx, &opa.OpaSpec{
+		Rego:    "package validate\n\nimport data.lula.labels as lula_labels\n\nvalidate { lula_labels.has_lula_label(input.pod) }\n\nnamed { input.pod.metadata.name }",
+		Modules: map[string]string{"lula.labels": "lula.rego"},
+		Tests:   []string{"validate_test.rego"},
+	})
+	require.NoError(t, err)
+
+	report := types.NewLulaValidationTestReport("opa")
+	provider.(types.TestableProvider).RunProviderTests(ctx, report)
+
+	require.Len(t, report.TestResults, 4)
+	results := make(map[string]*types.LulaValidationTestResult)
+	for _, result := range report.TestResults {
+		results[result.TestName] = result
+	}
+	require.Equal(t, &types.LulaValidationTestResult{
+		TestName: "data.validate_test.test_labeled",
+		Pass:     true,
+		Result:   "pass",
+		Remarks:  map[string]string{"location": "validate_test.rego:7"},
+	}, results["data.validate_test.test_labeled"])
+	require.True(t, results["data.validate_test.test_unlabeled"].Pass)
+	require.False(t, results["data.validate_test.test_fails"].Pass)
+	require.Equal(t, "fail", results["data.validate_test.test_fails"].Result)
+	require.Equal(t, []string{"pod without labels"}, results["data.validate_test.test_fails"].Prints)
+	require.True(t, results["data.validate_test.todo_test_exempt"].Pass)
+	require.Equal(t, "skip", results["data.validate_test.todo_test_exempt"].Result)
+
+	// the named rule is not covered by the tests
+	require.NotNil(t, report.Coverage)
+	require.Greater(t, *report.Coverage, 50.0)
+	require.Less(t, *report.Coverage, 100.0)
+
+	// errors loading the tests are reported as a failing test
+	provider, err = opa.CreateOpaProvider(ctx, &opa.OpaSpec{
+		Rego:  "package validate\n\nvalidate { true }",
+		Tests: []string{"missing_test.rego"},
+	})
+	require.NoError(t, err)
+	report = types.NewLulaValidationTestReport("opa")
+	provider.(types.TestableProvider).RunProviderTests(ctx, report)
+	require.Len(t, report.TestResults, 1)
+	require.False(t, report.TestResults[0].Pass)
+	require.Contains(t, report.TestResults[0].Remarks["error running opa tests"], opa.ErrLoadTests.Error())
+	require.Nil(t, report.Coverage)
+}
+
 var dummyPod = map[string]interface{}{
 	"pod": map[string]interface{}{
 		"metadata": map[string]interface{}{
@@ -0,0 +1,5 @@
+package lula.images
+
+allowed(image) {
+	startswith(image, data.lula.images.registries[_])
+}
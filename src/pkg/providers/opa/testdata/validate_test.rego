@@ -0,0 +1,22 @@
+package validate_test
+
+import rego.v1
+
+import data.validate
+
+test_labeled if {
+    validate.validate with input as {"pod": {"metadata": {"labels": {"lula": "true"}}}}
+}
+
+test_unlabeled if {
+    not validate.validate with input as {"pod": {"metadata": {"labels": {}}}}
+}
+
+test_fails if {
+    print("pod without labels")
+    validate.validate with input as {"pod": {"metadata": {}}}
+}
+
+todo_test_exempt if {
+    validate.validate with input as {"pod": {"metadata": {"name": "exempt"}}}
+}
@@ -0,0 +1,157 @@
+package opa
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/cover"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/open-policy-agent/opa/tester"
+
+	"github.com/mike-winberry/lulalib/src/pkg/common/network"
+	"github.com/mike-winberry/lulalib/src/types"
+)
+
+// opaTestsName is the name of the test result reported when the OPA tests cannot be run
+const opaTestsName = "opa tests"
+
+// RunProviderTests runs the OPA tests of the spec against the policy, its modules, data, and bundles, and
+// adds a result for each test and the coverage of the policy by the tests to the report
+func (o OpaProvider) RunProviderTests(ctx context.Context, report *types.LulaValidationTestReport) {
+	if len(o.Spec.Tests) == 0 {
+		return
+	}
+
+	results, coverage, err := o.runTests(ctx)
+	if err != nil {
+		report.AddTestResult(&types.LulaValidationTestResult{
+			TestName: opaTestsName,
+			Pass:     false,
+			Remarks: map[string]string{
+				"error running opa tests": err.Error(),
+			},
+		})
+		return
+	}
+
+	for _, result := range results {
+		report.AddTestResult(testResult(result))
+	}
+	report.Coverage = &coverage
+}
+
+// runTests runs the OPA tests and returns their results and the percentage of the lines of the policy,
+// excluding the tests, that they cover
+func (o OpaProvider) runTests(ctx context.Context) ([]*tester.Result, float64, error) {
+	modules, data, err := o.load(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if modules == nil {
+		modules = make(map[string]string)
+	}
+	modules[mainPolicyModuleName] = o.Spec.Rego
+
+	tests, err := loadTests(ctx, o.Spec.Tests, modules)
+	if err != nil {
+		return nil, 0, err
+	}
+	for name, test := range tests {
+		modules[name] = test
+	}
+
+	parsed, err := parseModules(modules)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	coverage := cover.New()
+	ch, err := tester.NewRunner().
+		SetCompiler(newCompiler(unsafeBuiltins(ctx))).
+		SetStore(inmem.NewFromObject(data)).
+		SetModules(parsed).
+		SetCoverageQueryTracer(coverage).
+		CapturePrintOutput(true).
+		RunTests(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrCompileRego, err)
+	}
+
+	var results []*tester.Result
+	for result := range ch {
+		results = append(results, result)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// only the lines of the policy count towards the coverage, not the lines of the tests
+	var covered, notCovered int
+	for name, file := range coverage.Report(parsed).Files {
+		if _, ok := tests[name]; ok {
+			continue
+		}
+		covered += file.CoveredLines
+		notCovered += file.NotCoveredLines
+	}
+	var percent float64
+	if covered+notCovered > 0 {
+		percent = 100 * float64(covered) / float64(covered+notCovered)
+	}
+
+	return results, percent, nil
+}
+
+// loadTests fetches the test files and returns their contents, keyed by their path
+func loadTests(ctx context.Context, paths []string, modules map[string]string) (map[string]string, error) {
+	workDir, ok := ctx.Value(types.LulaValidationWorkDir).(string)
+	if !ok { // if unset, assume lula is already working in the same directory the inputFile is in
+		workDir = "."
+	}
+
+	tests := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if _, ok := modules[path]; ok {
+			return nil, fmt.Errorf("%w %s: a module with the same name is already loaded", ErrLoadTests, path)
+		}
+		b, err := network.Fetch(path, network.WithBaseDir(workDir))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrLoadTests, path, err)
+		}
+		tests[path] = string(b)
+	}
+	return tests, nil
+}
+
+// testResult converts the result of an OPA test to a validation test result. Skipped tests, those
+// prefixed with todo_, pass.
+func testResult(result *tester.Result) *types.LulaValidationTestResult {
+	testResult := &types.LulaValidationTestResult{
+		TestName: fmt.Sprintf("%s.%s", result.Package, result.Name),
+		Pass:     !result.Fail && result.Error == nil,
+	}
+
+	switch {
+	case result.Error != nil:
+		testResult.Result = "error"
+		testResult.Remarks = map[string]string{"error": result.Error.Error()}
+	case result.Fail:
+		testResult.Result = "fail"
+	case result.Skip:
+		testResult.Result = "skip"
+	default:
+		testResult.Result = "pass"
+	}
+	if result.Location != nil {
+		if testResult.Remarks == nil {
+			testResult.Remarks = make(map[string]string)
+		}
+		testResult.Remarks["location"] = fmt.Sprintf("%s:%d", result.Location.File, result.Location.Row)
+	}
+	if output := strings.TrimRight(string(result.Output), "\n"); output != "" {
+		testResult.Prints = strings.Split(output, "\n")
+	}
+
+	return testResult
+}
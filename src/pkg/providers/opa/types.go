@@ -2,29 +2,54 @@ package opa
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/mike-winberry/lulalib/src/pkg/common/network"
 	"github.com/mike-winberry/lulalib/src/types"
 )
 
 var (
-	ErrNilSpec                = errors.New("spec is nil")
-	ErrEmptyRego              = errors.New("rego policy cannot be empty")
-	ErrInvalidValidationPath  = errors.New("validation field must be a json path")
-	ErrInvalidObservationPath = errors.New("observation field must be a json path")
-	ErrDownloadModule         = errors.New("error downloading module")
-	ErrReadModule             = errors.New("error reading module")
-	ErrReservedModuleName     = errors.New("module name is reserved and cannot be used in custom modules")
+	ErrNilSpec                  = errors.New("spec is nil")
+	ErrEmptyRego                = errors.New("rego policy cannot be empty")
+	ErrInvalidValidationPath    = errors.New("validation field must be a json path")
+	ErrInvalidObservationPath   = errors.New("observation field must be a json path")
+	ErrInvalidViolationsPath    = errors.New("violations field must be a json path")
+	ErrInvalidPassingPath       = errors.New("passing field must be a json path")
+	ErrValidationAndViolations  = errors.New("validation and violations fields cannot both be specified")
+	ErrPassingWithoutViolations = errors.New("passing field requires the violations field")
+	ErrDownloadModule           = errors.New("error downloading module")
+	ErrReadModule               = errors.New("error reading module")
+	ErrReservedModuleName       = errors.New("module name is reserved and cannot be used in custom modules")
+	ErrInvalidDataPath          = errors.New("data path must be a dot separated path")
+	ErrEmptyBundle              = errors.New("bundle cannot be empty")
+	ErrLoadData                 = errors.New("error loading data")
+	ErrLoadBundle               = errors.New("error loading bundle")
+	ErrEmptyTest                = errors.New("test cannot be empty")
+	ErrLoadTests                = errors.New("error loading tests")
 )
 
 type OpaProvider struct {
 	// Spec is the specification of the OPA policy
 	Spec *OpaSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+	// cache is the policy compiled from the spec, which is shared by copies of the provider
+	cache *policyCache
+}
+
+// policyCache holds the prepared policy of a provider, keyed by a hash of the spec it was prepared
+// from, the contents of its modules, data, and bundles, and the restricted built-ins it was allowed to call
+type policyCache struct {
+	mu     sync.Mutex
+	key    string
+	policy *preparedPolicy
 }
 
 func CreateOpaProvider(_ context.Context, spec *OpaSpec) (types.Provider, error) {
@@ -37,6 +62,26 @@ func CreateOpaProvider(_ context.Context, spec *OpaSpec) (types.Provider, error)
 		return nil, ErrEmptyRego
 	}
 
+	for path := range spec.Data {
+		for _, key := range strings.Split(path, ".") {
+			if key == "" {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidDataPath, path)
+			}
+		}
+	}
+
+	for _, bundle := range spec.Bundles {
+		if bundle == "" {
+			return nil, ErrEmptyBundle
+		}
+	}
+
+	for _, test := range spec.Tests {
+		if test == "" {
+			return nil, ErrEmptyTest
+		}
+	}
+
 	if spec.Output != nil {
 		if spec.Output.Validation != "" {
 			if !strings.Contains(spec.Output.Validation, ".") {
@@ -50,10 +95,27 @@ func CreateOpaProvider(_ context.Context, spec *OpaSpec) (types.Provider, error)
 				}
 			}
 		}
+		if spec.Output.Violations != "" {
+			if !strings.Contains(spec.Output.Violations, ".") {
+				return nil, ErrInvalidViolationsPath
+			}
+			if spec.Output.Validation != "" {
+				return nil, ErrValidationAndViolations
+			}
+		}
+		if spec.Output.Passing != "" {
+			if !strings.Contains(spec.Output.Passing, ".") {
+				return nil, ErrInvalidPassingPath
+			}
+			if spec.Output.Violations == "" {
+				return nil, ErrPassingWithoutViolations
+			}
+		}
 	}
 
 	return OpaProvider{
-		Spec: spec,
+		Spec:  spec,
+		cache: &policyCache{},
 	}, nil
 }
 
@@ -97,15 +159,108 @@ func loadModules(ctx context.Context, modulePaths map[string]string) (map[string
 }
 
 func (o OpaProvider) Evaluate(ctx context.Context, resources types.DomainResources) (types.Result, error) {
-	modules, err := loadModules(ctx, o.Spec.Modules)
+	if len(resources) == 0 {
+		return types.Result{}, errNoResources
+	}
+
+	policy, err := o.prepare(ctx)
 	if err != nil {
 		return types.Result{}, err
 	}
-	results, err := GetValidatedAssets(ctx, o.Spec.Rego, modules, resources, o.Spec.Output)
+	return policy.evaluate(ctx, resources)
+}
+
+// prepare returns the prepared policy of the spec. Its modules, data, and bundles are loaded each time,
+// and it is only compiled again if their contents, the spec, or the allowed built-ins have changed since it
+// was last prepared.
+func (o OpaProvider) prepare(ctx context.Context) (*preparedPolicy, error) {
+	modules, data, err := o.load(ctx)
 	if err != nil {
-		return types.Result{}, err
+		return nil, err
+	}
+	allowed, _ := ctx.Value(types.LulaValidationAllowedBuiltins).([]string)
+	key, err := cacheKey(o.Spec, modules, data, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.cache != nil {
+		o.cache.mu.Lock()
+		defer o.cache.mu.Unlock()
+		if o.cache.policy != nil && o.cache.key == key {
+			return o.cache.policy, nil
+		}
+	}
+
+	policy, err := preparePolicy(ctx, o.Spec.Rego, modules, data, o.Spec.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.cache != nil {
+		o.cache.key = key
+		o.cache.policy = policy
+	}
+	return policy, nil
+}
+
+// load loads the modules, data documents, and bundles of the spec, returning the modules, including
+// those of the bundles, and the data document
+func (o OpaProvider) load(ctx context.Context) (map[string]string, map[string]interface{}, error) {
+	modules, err := loadModules(ctx, o.Spec.Modules)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := loadData(ctx, o.Spec.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(o.Spec.Bundles) > 0 {
+		if modules == nil {
+			modules = make(map[string]string)
+		}
+		if err := loadBundles(ctx, o.Spec.Bundles, modules, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	return modules, data, nil
+}
+
+// cacheKey returns a hash of the spec, the loaded modules and data, including those of its bundles, and
+// the restricted built-ins it is allowed to call
+func cacheKey(spec *OpaSpec, modules map[string]string, data map[string]interface{}, allowed []string) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(b)
+
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(modules[name]))
+	}
+
+	// maps are marshaled with sorted keys, so equal data has the same hash
+	b, err = json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	hash.Write([]byte{0})
+	hash.Write(b)
+
+	for _, name := range allowed {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
 	}
-	return results, nil
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // OpaSpec is the specification of the OPA policy, required if the provider type is opa
@@ -116,6 +271,16 @@ type OpaSpec struct {
 	// module and the value is the file with the contents of the module. The `validate.rego` module
 	// name is reserved and cannot be used in custom modules.
 	Modules map[string]string `json:"modules,omitempty" yaml:"modules,omitempty"`
+	// Optional: Data is a map of data documents to include. The key is the dot separated path the
+	// document is loaded under, e.g. `lula.exemptions` for `data.lula.exemptions`, and the value is the
+	// JSON or YAML file with the contents of the document.
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+	// Optional: Bundles is a list of OPA bundles to include, each a directory or a tar.gz file. The
+	// modules and data of each bundle are loaded with the policy.
+	Bundles []string `json:"bundles,omitempty" yaml:"bundles,omitempty"`
+	// Optional: Tests is a list of files with OPA tests of the policy, e.g. `validate_test.rego`. They
+	// are run with the tests of the validation, and report the coverage of the policy.
+	Tests []string `json:"tests,omitempty" yaml:"tests,omitempty"`
 	// Optional: Output is the output of the OPA policy
 	Output *OpaOutput `json:"output,omitempty" yaml:"output,omitempty"`
 }
@@ -124,6 +289,12 @@ type OpaSpec struct {
 type OpaOutput struct {
 	// optional: Specifies the JSON path to a boolean value indicating the validation result.
 	Validation string `json:"validation" yaml:"validation"`
-	// optional: any additional observations to include (fields must resolve to strings)
+	// optional: any additional observations to include. Values that are not strings are kept as structured data
 	Observations []string `json:"observations" yaml:"observations"`
+	// optional: Specifies the JSON path to a set or array of violations. Each violation is a failing result
+	// and an observation, and the validation field is not used.
+	Violations string `json:"violations,omitempty" yaml:"violations,omitempty"`
+	// optional: Specifies the JSON path to a set or array of passing items, each of which is a passing result.
+	// Requires violations.
+	Passing string `json:"passing,omitempty" yaml:"passing,omitempty"`
 }
@@ -66,6 +66,82 @@ func TestCreateOpaProvider(t *testing.T) {
 			},
 			wantErr: opa.ErrInvalidObservationPath,
 		},
+		{
+			name: "valid spec with violations",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndeny := set()",
+				Output: &opa.OpaOutput{
+					Violations: "validate.deny",
+					Passing:    "validate.compliant",
+				},
+			},
+		},
+		{
+			name: "invalid violations path",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndeny := set()",
+				Output: &opa.OpaOutput{
+					Violations: "deny",
+				},
+			},
+			wantErr: opa.ErrInvalidViolationsPath,
+		},
+		{
+			name: "invalid passing path",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndeny := set()",
+				Output: &opa.OpaOutput{
+					Violations: "validate.deny",
+					Passing:    "compliant",
+				},
+			},
+			wantErr: opa.ErrInvalidPassingPath,
+		},
+		{
+			name: "validation and violations",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndeny := set()",
+				Output: &opa.OpaOutput{
+					Validation: "validate.validate",
+					Violations: "validate.deny",
+				},
+			},
+			wantErr: opa.ErrValidationAndViolations,
+		},
+		{
+			name: "passing without violations",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndeny := set()",
+				Output: &opa.OpaOutput{
+					Passing: "validate.compliant",
+				},
+			},
+			wantErr: opa.ErrPassingWithoutViolations,
+		},
+		{
+			name: "valid spec with data and bundles",
+			spec: &opa.OpaSpec{
+				Rego:    "package validate\n\ndefault validate = false",
+				Data:    map[string]string{"lula.exemptions": "exemptions.yaml"},
+				Bundles: []string{"bundle.tar.gz"},
+			},
+		},
+		{
+			name: "invalid data path",
+			spec: &opa.OpaSpec{
+				Rego: "package validate\n\ndefault validate = false",
+				Data: map[string]string{"lula..exemptions": "exemptions.yaml"},
+			},
+			wantErr: opa.ErrInvalidDataPath,
+		},
+		{
+			name: "empty bundle",
+			spec: &opa.OpaSpec{
+				Rego:    "package validate\n\ndefault validate = false",
+				Bundles: []string{""},
+			},
+			wantErr: opa.ErrEmptyBundle,
+		},
 	}
 
 	for _, tt := range tests {
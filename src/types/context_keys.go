@@ -4,4 +4,8 @@ type contextKey int
 
 const (
 	LulaValidationWorkDir contextKey = iota
+	// LulaValidationTrace enables capturing an evaluation trace of the validation, if supported by the provider
+	LulaValidationTrace
+	// LulaValidationAllowedBuiltins is the []string of restricted OPA built-in functions that policies are allowed to call
+	LulaValidationAllowedBuiltins
 )
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
 
 	"github.com/mike-winberry/lulalib/src/pkg/message"
 )
@@ -50,6 +54,9 @@ type LulaValidation struct {
 
 	// Result is the result of the validation
 	Result *Result
+
+	// providerTestReport is the report of the tests of the provider itself, which are run once
+	providerTestReport *LulaValidationTestReport
 }
 
 // CreateFailingLulaValidation creates a placeholder LulaValidation object that is always failing
@@ -178,36 +185,50 @@ func (v *LulaValidation) Validate(ctx context.Context, opts ...LulaValidationOpt
 
 // RunTests executes any tests defined in the validation and returns a report of the results
 func (v *LulaValidation) RunTests(ctx context.Context, saveResources bool) (*LulaValidationTestReport, error) {
-	if v.DomainResources == nil {
-		return nil, fmt.Errorf("domain resources are nil, tests cannot be run")
-	}
+	testReport := NewLulaValidationTestReport(v.Name)
 
 	// For each test, apply the transforms to the domain resources and run validate using those resources
-	if len(v.ValidationTestData) != 0 {
-		testReport := NewLulaValidationTestReport(v.Name)
-		for _, d := range v.ValidationTestData {
-			// Only execute test if it has not been executed yet
-			if d.Test != nil && d.Result == nil {
-				// Create a fresh copy of the resources and validation to run each test on
-				testResources := deepCopyMap(*v.DomainResources)
-				testValidation := &LulaValidation{
-					Provider: v.Provider,
-				}
+	for _, d := range v.ValidationTestData {
+		// Only execute test if it has not been executed yet
+		if d.Test != nil && d.Result == nil {
+			if v.DomainResources == nil {
+				return nil, fmt.Errorf("domain resources are nil, tests cannot be run")
+			}
 
-				// Execute the test
-				testResult, err := d.ExecuteTest(ctx, testValidation, testResources, saveResources)
-				if err != nil {
-					return nil, err
-				}
-				testReport.AddTestResult(testResult)
-			} else if d.Result != nil {
-				testReport.AddTestResult(d.Result)
+			// Create a fresh copy of the resources and validation to run each test on
+			testResources := deepCopyMap(*v.DomainResources)
+			testValidation := &LulaValidation{
+				Provider: v.Provider,
+			}
+
+			// Execute the test
+			testResult, err := d.ExecuteTest(ctx, testValidation, testResources, saveResources)
+			if err != nil {
+				return nil, err
 			}
+			testReport.AddTestResult(testResult)
+		} else if d.Result != nil {
+			testReport.AddTestResult(d.Result)
 		}
-		return testReport, nil
 	}
 
-	return nil, nil
+	// Run the tests of the provider itself, e.g., OPA policy tests, which do not use the domain resources
+	if v.providerTestReport == nil && v.Provider != nil {
+		if provider, ok := (*v.Provider).(TestableProvider); ok {
+			v.providerTestReport = NewLulaValidationTestReport(v.Name)
+			provider.RunProviderTests(ctx, v.providerTestReport)
+		}
+	}
+	if v.providerTestReport != nil {
+		testReport.TestResults = append(testReport.TestResults, v.providerTestReport.TestResults...)
+		testReport.Coverage = v.providerTestReport.Coverage
+		testReport.ProviderCoverage = v.providerTestReport.ProviderCoverage
+	}
+
+	if len(testReport.TestResults) == 0 && testReport.Coverage == nil && len(testReport.ProviderCoverage) == 0 {
+		return nil, nil
+	}
+	return testReport, nil
 }
 
 // Check if the validation requires confirmation before possible execution code is run
@@ -237,6 +258,13 @@ type Provider interface {
 	Evaluate(context.Context, DomainResources) (Result, error)
 }
 
+// TestableProvider is a Provider with tests of its own, such as OPA policy tests, which are run with
+// the tests of the validation. Their results, including any errors running them, are added to the report.
+type TestableProvider interface {
+	Provider
+	RunProviderTests(ctx context.Context, report *LulaValidationTestReport)
+}
+
 // native type for conversion to targeted report format
 type Result struct {
 	UUID         string            `json:"uuid" yaml:"uuid"`
@@ -246,6 +274,71 @@ type Result struct {
 	Failing      int               `json:"failing" yaml:"failing"`
 	State        string            `json:"state" yaml:"state"`
 	Observations map[string]string `json:"observations" yaml:"observations"`
+	// ObservationData holds the typed value of observations that are not strings, keyed the same as
+	// Observations, which holds their readable rendering
+	ObservationData map[string]interface{} `json:"observation-data,omitempty" yaml:"observation-data,omitempty"`
+	// Prints is the output of print statements in the policy, captured when debugging
+	Prints []string `json:"prints,omitempty" yaml:"prints,omitempty"`
+	// Trace is the evaluation trace of the validation, captured when tracing
+	Trace string `json:"trace,omitempty" yaml:"trace,omitempty"`
+}
+
+// AddObservation adds an observation to the result. Strings are added as is, and any other value is
+// kept in ObservationData and added to Observations rendered as YAML.
+func (r *Result) AddObservation(key string, value interface{}) {
+	if r.Observations == nil {
+		r.Observations = make(map[string]string)
+	}
+	if s, ok := value.(string); ok {
+		r.Observations[key] = s
+		return
+	}
+
+	if r.ObservationData == nil {
+		r.ObservationData = make(map[string]interface{})
+	}
+	r.ObservationData[key] = value
+	rendered, err := yaml.Marshal(value)
+	if err != nil {
+		message.Debugf("Error marshalling observation %s to YAML: %v", key, err)
+		r.Observations[key] = fmt.Sprintf("%v", value)
+		return
+	}
+	r.Observations[key] = strings.TrimSuffix(string(rendered), "\n")
+}
+
+// ItemKey returns the observation key of the item at index i of n items observed under key, e.g. key[07].
+// The index is zero-padded so that the keys of the items sort in their order.
+func ItemKey(key string, i, n int) string {
+	width := len(strconv.Itoa(n - 1))
+	return fmt.Sprintf("%s[%0*d]", key, width, i)
+}
+
+// AddItemResults adds a result for each of n items, and observes them under key as "PASS: <n> items" or
+// "FAIL: <k> of <n> items". An empty list is a single passing result. The evaluate function returns
+// whether the item at index i passes, and observes any failure of the item under itemKey.
+func (r *Result) AddItemResults(key string, n int, evaluate func(i int, itemKey string) bool) {
+	if n == 0 {
+		r.Passing += 1
+		r.AddObservation(key, "PASS: 0 items")
+		return
+	}
+
+	failing := 0
+	for i := 0; i < n; i++ {
+		if evaluate(i, ItemKey(key, i, n)) {
+			r.Passing += 1
+		} else {
+			failing += 1
+		}
+	}
+	r.Failing += failing
+
+	if failing > 0 {
+		r.AddObservation(key, fmt.Sprintf("FAIL: %d of %d items", failing, n))
+	} else {
+		r.AddObservation(key, fmt.Sprintf("PASS: %d items", n))
+	}
 }
 
 func deepCopyMap(input map[string]interface{}) map[string]interface{} {
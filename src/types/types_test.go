@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -321,3 +322,100 @@ func TestRunTests(t *testing.T) {
 		})
 	}
 }
+
+// TestRunTestsWithProviderTests checks that the tests of the provider are run once with the tests of the validation
+func TestRunTestsWithProviderTests(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	test := "package validate_test\n\nimport data.validate\n\ntest_named {\n  validate.validate with input as {\"test\": {\"metadata\": {\"name\": \"test-resource\"}}}\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "validate_test.rego"), []byte(test), 0600))
+	ctx := context.WithValue(context.Background(), types.LulaValidationWorkDir, dir)
+
+	provider, err := opa.CreateOpaProvider(ctx, &opa.OpaSpec{
+		Rego:  "package validate\n\nvalidate {input.test.metadata.name == \"test-resource\"}",
+		Tests: []string{"validate_test.rego"},
+	})
+	require.NoError(t, err)
+
+	// the provider tests do not need the domain resources
+	validation := types.LulaValidation{
+		Name:     "test-validation",
+		Provider: &provider,
+	}
+	testReport, err := validation.RunTests(ctx, false)
+	require.NoError(t, err)
+
+	// the provider tests are run once, so the removed test file is not loaded again
+	require.NoError(t, os.Remove(filepath.Join(dir, "validate_test.rego")))
+	rerunReport, err := validation.RunTests(ctx, false)
+	require.NoError(t, err)
+	require.Equal(t, testReport, rerunReport)
+
+	coverage := 100.0
+	require.Equal(t, &types.LulaValidationTestReport{
+		Name: "test-validation",
+		TestResults: []*types.LulaValidationTestResult{
+			{
+				TestName: "data.validate_test.test_named",
+				Pass:     true,
+				Result:   "pass",
+				Remarks:  map[string]string{"location": "validate_test.rego:5"},
+			},
+		},
+		Coverage: &coverage,
+	}, testReport)
+}
+
+func TestAddItemResults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		items []bool
+		want  types.Result
+	}{
+		{
+			name:  "no items",
+			items: nil,
+			want: types.Result{
+				Passing:      1,
+				Observations: map[string]string{"items": "PASS: 0 items"},
+			},
+		},
+		{
+			name:  "passing items",
+			items: []bool{true, true},
+			want: types.Result{
+				Passing:      2,
+				Observations: map[string]string{"items": "PASS: 2 items"},
+			},
+		},
+		{
+			name:  "failing items",
+			items: []bool{true, false, true, true, true, true, true, true, true, true, false},
+			want: types.Result{
+				Passing: 9,
+				Failing: 2,
+				Observations: map[string]string{
+					"items":     "FAIL: 2 of 11 items",
+					"items[01]": "FAIL",
+					"items[10]": "FAIL",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result types.Result
+			result.AddItemResults("items", len(tt.items), func(i int, itemKey string) bool {
+				if !tt.items[i] {
+					result.AddObservation(itemKey, "FAIL")
+				}
+				return tt.items[i]
+			})
+			require.Equal(t, tt.want, result)
+		})
+	}
+}
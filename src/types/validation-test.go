@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	"github.com/mike-winberry/lulalib/src/internal/transform"
 	"github.com/mike-winberry/lulalib/src/pkg/message"
@@ -131,6 +132,8 @@ func (d *LulaValidationTestData) ExecuteTest(ctx context.Context, validation *Lu
 	d.Result.Result = result
 	d.Result.Pass = d.Test.ExpectedResult == result
 	d.Result.Remarks = validation.Result.Observations
+	d.Result.Prints = validation.Result.Prints
+	d.Result.Trace = validation.Result.Trace
 
 	return d.Result, nil
 }
@@ -143,12 +146,19 @@ type LulaValidationTestResult struct {
 	Result            string            `json:"result" yaml:"result"`
 	Remarks           map[string]string `json:"remarks,omitempty" yaml:"remarks,omitempty"`
 	TestResourcesPath string            `json:"test-resources-path,omitempty" yaml:"test-resources-path,omitempty"`
+	// Prints and Trace are the debugging output of the validation when run for the test
+	Prints []string `json:"prints,omitempty" yaml:"prints,omitempty"`
+	Trace  string   `json:"trace,omitempty" yaml:"trace,omitempty"`
 }
 
 // LulaValidationTestReport contains the report of all the tests performed on a LulaValidation
 type LulaValidationTestReport struct {
 	Name        string                      `json:"name" yaml:"name"`
 	TestResults []*LulaValidationTestResult `json:"test-results" yaml:"test-results"`
+	// Coverage is the percentage of the provider policy covered by the tests of the provider, if reported
+	Coverage *float64 `json:"coverage,omitempty" yaml:"coverage,omitempty"`
+	// ProviderCoverage is the coverage reported by each of multiple providers, keyed by provider name
+	ProviderCoverage map[string]float64 `json:"provider-coverage,omitempty" yaml:"provider-coverage,omitempty"`
 }
 
 // NewLulaValidationTestReport creates a new report for a Lula Validation
@@ -187,10 +197,27 @@ func (r *LulaValidationTestReport) PrintReport() {
 		for remark, value := range testResult.Remarks {
 			message.Infof("--> %s: %s", remark, value)
 		}
+		for _, msg := range testResult.Prints {
+			message.Infof("--> print: %s", msg)
+		}
+		if testResult.Trace != "" {
+			message.Infof("Trace:\n%s", testResult.Trace)
+		}
 		if testResult.TestResourcesPath != "" {
 			message.Infof("Test Resources File Path: %s", testResult.TestResourcesPath)
 		}
 	}
+	if r.Coverage != nil {
+		message.Infof("Policy coverage: %.2f%%", *r.Coverage)
+	}
+	names := make([]string, 0, len(r.ProviderCoverage))
+	for name := range r.ProviderCoverage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		message.Infof("Policy coverage of %s: %.2f%%", name, r.ProviderCoverage[name])
+	}
 }
 
 func (r *LulaValidationTestReport) TestFailed() bool {